@@ -1,16 +1,50 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diogo/droid-config/internal/cli"
+	"github.com/diogo/droid-config/internal/config"
 	"github.com/diogo/droid-config/internal/ui"
 )
 
 func main() {
+	fs := flag.NewFlagSet("droid-config", flag.ContinueOnError)
+	configFlag := fs.String("config", "", "path to the config file (default $"+config.EnvConfigPath+" or ~/.factory/"+config.ConfigFileName+")")
+	fs.Usage = func() {
+		cli.Run("", []string{"help"}, os.Stderr, os.Stderr)
+	}
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		if err == flag.ErrHelp {
+			os.Exit(0)
+		}
+		os.Exit(2)
+	}
+
+	if fs.NArg() > 0 {
+		os.Exit(cli.Run(*configFlag, fs.Args(), os.Stdout, os.Stderr))
+	}
+
+	path, err := config.ResolvePath(*configFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if keysPath, err := config.KeysPath(); err == nil {
+		keys, err := ui.LoadKeyMap(keysPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: key bindings in %s:\n%v\n", config.DisplayPath(keysPath), err)
+			os.Exit(1)
+		}
+		ui.Keys = keys
+	}
+
 	p := tea.NewProgram(
-		ui.NewModel(),
+		ui.NewModel(path),
 		tea.WithAltScreen(),
 		tea.WithMouseCellMotion(),
 	)
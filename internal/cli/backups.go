@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/diogo/droid-config/internal/config"
+)
+
+func runRestore(r *runner, args []string) error {
+	fs := r.newFlagSet("restore", "[<backup number|file name>] [flags]")
+	diff := fs.Bool("diff", false, "show changes between the backup and the current file instead of restoring")
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 1 {
+		fs.Usage()
+		return errUsage
+	}
+
+	path, err := config.ResolvePath(r.configPath)
+	if err != nil {
+		return err
+	}
+	backups, err := config.ListBackups(path)
+	if err != nil {
+		return err
+	}
+
+	if len(rest) == 0 {
+		if len(backups) == 0 {
+			fmt.Fprintf(r.stdout, "No backups in %s\n", config.BackupDir(path))
+			return nil
+		}
+		for i, b := range backups {
+			fmt.Fprintf(r.stdout, "%d. %s\t%s\t%d bytes\n", i+1, b.Time.Format("2006-01-02 15:04:05"), b.Name(), b.Size)
+		}
+		return nil
+	}
+
+	b, err := resolveBackup(backups, rest[0])
+	if err != nil {
+		return err
+	}
+
+	if *diff {
+		return printDiff(r, path, b)
+	}
+
+	if err := config.RestoreBackup(path, b, r.saveOptions()); err != nil {
+		return err
+	}
+	fmt.Fprintf(r.stdout, "Restored %s from %s\n", path, b.Name())
+	return nil
+}
+
+// resolveBackup finds a backup by its 1-based position in the listing
+// (newest first) or by file name.
+func resolveBackup(backups []config.Backup, ref string) (config.Backup, error) {
+	if n, err := strconv.Atoi(ref); err == nil {
+		if n < 1 || n > len(backups) {
+			return config.Backup{}, fmt.Errorf("backup %d out of range (1-%d)", n, len(backups))
+		}
+		return backups[n-1], nil
+	}
+	for _, b := range backups {
+		if b.Name() == filepath.Base(ref) {
+			return b, nil
+		}
+	}
+	return config.Backup{}, fmt.Errorf("no backup named %q", ref)
+}
+
+func printDiff(r *runner, path string, b config.Backup) error {
+	current, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	old, err := os.ReadFile(b.Path)
+	if err != nil {
+		return err
+	}
+
+	diff := config.DiffLines(string(current), string(old))
+	if !config.HasChanges(diff) {
+		fmt.Fprintln(r.stdout, "Backup is identical to the current file")
+		return nil
+	}
+	fmt.Fprintf(r.stdout, "--- %s (current)\n+++ %s\n", path, b.Name())
+	for _, d := range diff {
+		fmt.Fprintln(r.stdout, d.String())
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+// Package cli implements the non-interactive subcommands of droid-config,
+// used for scripting model management without starting the TUI.
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/diogo/droid-config/internal/config"
+)
+
+var (
+	// errUsage signals that a usage message has already been printed.
+	errUsage = errors.New("usage")
+	// errReported signals that the command has already explained its failure.
+	errReported = errors.New("reported")
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(r *runner, args []string) error
+}
+
+// runner carries the state shared by every subcommand.
+type runner struct {
+	configPath string
+	force      bool
+	wait       time.Duration
+	lock       *config.Lock
+	stdout     io.Writer
+	stderr     io.Writer
+}
+
+// defaultLockWait is how long a bare --wait waits for the config lock.
+const defaultLockWait = 30 * time.Second
+
+// waitFlag is the --wait flag: "--wait" alone waits defaultLockWait,
+// "--wait=10s" any duration and "--wait=false" not at all.
+type waitFlag struct{ d *time.Duration }
+
+func (w waitFlag) IsBoolFlag() bool { return true }
+
+func (w waitFlag) String() string {
+	if w.d == nil || *w.d == 0 {
+		return ""
+	}
+	return w.d.String()
+}
+
+func (w waitFlag) Set(value string) error {
+	if b, err := strconv.ParseBool(value); err == nil {
+		*w.d = 0
+		if b {
+			*w.d = defaultLockWait
+		}
+		return nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return fmt.Errorf("expected a duration such as 10s")
+	}
+	*w.d = d
+	return nil
+}
+
+// load reads the config file. With --force an unparseable file is treated as
+// empty so that the following save replaces it.
+func (r *runner) load() (*config.ConfigData, string, error) {
+	path, err := config.ResolvePath(r.configPath)
+	if err != nil {
+		return nil, "", err
+	}
+	cfg, err := config.Load(path)
+	var parseErr *config.ParseError
+	if r.force && errors.As(err, &parseErr) {
+		fmt.Fprintf(r.stderr, "Warning: ignoring unparseable %s\n", path)
+		return &config.ConfigData{CustomModels: []config.CustomModel{}}, path, nil
+	}
+	return cfg, path, err
+}
+
+// loadForUpdate locks the config file and then loads it, so that no other
+// process can write between this load and the following save. The lock is
+// released when the command returns.
+func (r *runner) loadForUpdate() (*config.ConfigData, string, error) {
+	path, err := config.ResolvePath(r.configPath)
+	if err != nil {
+		return nil, "", err
+	}
+	lock, err := config.LockFile(path, r.wait)
+	if err != nil {
+		if errors.Is(err, config.ErrLocked) && r.wait == 0 {
+			return nil, "", fmt.Errorf("%w; retry with --wait", err)
+		}
+		return nil, "", err
+	}
+	r.lock = lock
+	return r.load()
+}
+
+func (r *runner) save(path string, cfg *config.ConfigData) error {
+	return config.SaveWith(path, cfg, r.saveOptions())
+}
+
+func (r *runner) saveOptions() config.SaveOptions {
+	return config.SaveOptions{Force: r.force, Wait: r.wait, Locked: r.lock != nil}
+}
+
+func commands() []command {
+	return []command{
+		{"list", "List configured models", runList},
+		{"show", "Show a single model", runShow},
+		{"add", "Add a new model", runAdd},
+		{"edit", "Change fields of an existing model", runEdit},
+		{"remove", "Remove one or more models", runRemove},
+		{"move", "Move a model to another position", runMove},
+		{"restore", "List, diff or restore config backups", runRestore},
+		{"import", "Import models from other tools' config files", runImport},
+		{"export", "Export models to a shareable JSON or YAML bundle", runExport},
+		{"validate", "Check the config file for errors (alias: doctor)", runValidate},
+		{"doctor", "", runValidate},
+	}
+}
+
+// Run executes the subcommand named by args[0] against the config file at
+// configPath (empty for the default location) and returns the process exit code.
+func Run(configPath string, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage(stdout)
+		return 0
+	}
+
+	for _, c := range commands() {
+		if c.name != args[0] {
+			continue
+		}
+		r := &runner{configPath: configPath, stdout: stdout, stderr: stderr}
+		err := c.run(r, args[1:])
+		r.lock.Unlock()
+		if err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return 0
+			}
+			if !errors.Is(err, errUsage) && !errors.Is(err, errReported) {
+				printError(stderr, err)
+			}
+			return 1
+		}
+		return 0
+	}
+
+	fmt.Fprintf(stderr, "Error: unknown command %q\n\n", args[0])
+	printUsage(stderr)
+	return 2
+}
+
+func printError(w io.Writer, err error) {
+	fmt.Fprintf(w, "Error: %v\n", err)
+
+	var parseErr *config.ParseError
+	if errors.As(err, &parseErr) {
+		fmt.Fprintln(w)
+		for _, line := range parseErr.Context(2) {
+			fmt.Fprintln(w, "  "+line)
+		}
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Fix the file by hand, or run 'droid-config add --force ...' to replace it.")
+	}
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: droid-config [--config path] [command] [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Without a command the interactive editor is started.")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, c := range commands() {
+		if c.summary != "" {
+			fmt.Fprintf(w, "  %-9s %s\n", c.name, c.summary)
+		}
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Models are referenced by their 1-based position or display name.")
+	fmt.Fprintf(w, "The config file defaults to ~/.factory/%s and can be changed with\n", config.ConfigFileName)
+	fmt.Fprintf(w, "--config or the %s environment variable.\n", config.EnvConfigPath)
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Every write keeps a backup in the backups/ directory next to the config\n")
+	fmt.Fprintf(w, "file; %s sets how many are kept (default %d, 0 disables).\n", config.EnvBackupCount, config.DefaultBackupCount)
+	fmt.Fprintln(w, "Writes are locked against other droid-config processes; pass --wait to")
+	fmt.Fprintln(w, "wait for a busy lock instead of failing.")
+	fmt.Fprintln(w, "Run 'droid-config <command> -h' for command flags.")
+}
+
+// newFlagSet returns a flag set that reports errors to stderr without exiting.
+// Every subcommand also accepts --config, overriding the global one.
+func (r *runner) newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(r.stderr)
+	fs.StringVar(&r.configPath, "config", r.configPath, "path to the config file")
+	fs.Var(waitFlag{&r.wait}, "wait", "wait for another process to release the config lock (default 30s, or e.g. --wait=5s)")
+	fs.Usage = func() {
+		fmt.Fprintf(r.stderr, "Usage: droid-config %s %s\n\nFlags:\n", name, usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseArgs parses flags interspersed with positional arguments, so that
+// "edit 'My Model' --model x" works the same as "edit --model x 'My Model'".
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, errUsage
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		if args[0] == "--" {
+			return append(positional, args[1:]...), nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func expectArgs(fs *flag.FlagSet, args []string, n int) error {
+	if len(args) != n {
+		fs.Usage()
+		return errUsage
+	}
+	return nil
+}
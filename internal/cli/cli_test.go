@@ -0,0 +1,417 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diogo/droid-config/internal/config"
+)
+
+func setupConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), config.ConfigFileName)
+	if content != "" {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv(config.EnvConfigPath, path)
+	return path
+}
+
+func run(t *testing.T, args ...string) (string, int) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := Run("", args, &stdout, &stderr)
+	return stdout.String() + stderr.String(), code
+}
+
+// addArgs returns the arguments adding a valid model called name.
+func addArgs(name string, extra ...string) []string {
+	return append([]string{"add", "--name", name, "--model", "gpt-4o", "--base-url", "https://api.openai.com/v1"}, extra...)
+}
+
+func loadModels(t *testing.T, path string) []config.CustomModel {
+	t.Helper()
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	return cfg.CustomModels
+}
+
+func TestAddEditRemove(t *testing.T) {
+	path := setupConfig(t, `{"other": true}`)
+
+	if out, code := run(t, "add", "--name", "First", "--model", "gpt-4", "--base-url", "https://api.openai.com/v1", "--provider", "openai", "--max-tokens", "4096"); code != 0 {
+		t.Fatalf("add failed (%d): %s", code, out)
+	}
+	if out, code := run(t, "add", "--name", "Second", "--model", "claude", "--base-url", "https://api.anthropic.com", "--provider", "anthropic", "--position", "1"); code != 0 {
+		t.Fatalf("add failed (%d): %s", code, out)
+	}
+
+	models := loadModels(t, path)
+	if len(models) != 2 || models[0].DisplayName != "Second" || models[1].DisplayName != "First" {
+		t.Fatalf("Unexpected models after add: %+v", models)
+	}
+
+	if out, code := run(t, "edit", "first", "--base-url", "https://api.test.com"); code != 0 {
+		t.Fatalf("edit failed (%d): %s", code, out)
+	}
+	models = loadModels(t, path)
+	if models[1].BaseURL != "https://api.test.com" || models[1].Model != "gpt-4" {
+		t.Errorf("edit did not apply only the given flag: %+v", models[1])
+	}
+
+	if out, code := run(t, "remove", "1"); code != 0 {
+		t.Fatalf("remove failed (%d): %s", code, out)
+	}
+	models = loadModels(t, path)
+	if len(models) != 1 || models[0].DisplayName != "First" {
+		t.Errorf("Unexpected models after remove: %+v", models)
+	}
+}
+
+func TestMove(t *testing.T) {
+	path := setupConfig(t, `{"custom_models": [
+		{"model_display_name": "A", "provider": "openai"},
+		{"model_display_name": "B", "provider": "openai"},
+		{"model_display_name": "C", "provider": "openai"}
+	]}`)
+
+	if out, code := run(t, "move", "C", "top"); code != 0 {
+		t.Fatalf("move failed (%d): %s", code, out)
+	}
+	if out, code := run(t, "move", "A", "3"); code != 0 {
+		t.Fatalf("move failed (%d): %s", code, out)
+	}
+
+	var names []string
+	for _, m := range loadModels(t, path) {
+		names = append(names, m.DisplayName)
+	}
+	if got := strings.Join(names, ","); got != "C,B,A" {
+		t.Errorf("Expected order C,B,A, got %s", got)
+	}
+}
+
+func TestRejectsInvalidInput(t *testing.T) {
+	path := setupConfig(t, `{"custom_models": [{"model_display_name": "A", "model": "m", "base_url": "https://a.test/v1", "provider": "openai"}]}`)
+
+	cases := [][]string{
+		{"add", "--model", "x"},
+		{"add", "--name", "X", "--provider", "nope"},
+		{"add", "--name", "X", "--base-url", "https://api.openai.com/v1"},
+		addArgs("X", "--base-url", "ftp://example.com"),
+		addArgs("a"),
+		{"edit", "A", "--base-url", "api.example.com"},
+		{"edit", "missing", "--model", "x"},
+		{"show", "5"},
+		{"bogus"},
+	}
+	for _, args := range cases {
+		if _, code := run(t, args...); code == 0 {
+			t.Errorf("Expected %v to fail", args)
+		}
+	}
+	if models := loadModels(t, path); len(models) != 1 || models[0].BaseURL != "https://a.test/v1" {
+		t.Errorf("Expected rejected commands not to write, got %+v", models)
+	}
+
+	out, code := run(t, addArgs("B")...)
+	if code != 0 || !strings.Contains(out, "Warning: api_key: no API key set") {
+		t.Errorf("Expected add to succeed with a warning, got (%d) %q", code, out)
+	}
+}
+
+func TestEditWithoutFieldFlags(t *testing.T) {
+	content := `{"custom_models": [{"model_display_name": "A", "model": "m", "base_url": "https://a.test/v1", "provider": "openai"}]}`
+	path := setupConfig(t, content)
+
+	for _, args := range [][]string{
+		{"edit", "A", "--config", path},
+		{"edit", "A", "--wait"},
+	} {
+		out, code := run(t, args...)
+		if code == 0 || !strings.Contains(out, "nothing to change") {
+			t.Errorf("Expected %v to fail with nothing to change, got (%d) %q", args, code, out)
+		}
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != content {
+		t.Errorf("Expected the file untouched, got %q (%v)", data, err)
+	}
+	if backups, _ := config.ListBackups(path); len(backups) > 0 {
+		t.Errorf("Expected no backup, got %v", backups)
+	}
+}
+
+func TestShowMasksKey(t *testing.T) {
+	setupConfig(t, `{"custom_models": [{"model_display_name": "A", "api_key": "sk-1234567890abcdef", "provider": "openai"}]}`)
+
+	out, code := run(t, "show", "A")
+	if code != 0 {
+		t.Fatalf("show failed (%d): %s", code, out)
+	}
+	if strings.Contains(out, "sk-1234567890abcdef") {
+		t.Error("API key was printed without --reveal")
+	}
+
+	out, _ = run(t, "show", "A", "--reveal")
+	if !strings.Contains(out, "sk-1234567890abcdef") {
+		t.Error("API key was not printed with --reveal")
+	}
+}
+
+func TestConfigFlagOverridesEnv(t *testing.T) {
+	setupConfig(t, `{"custom_models": [{"model_display_name": "FromEnv", "provider": "openai"}]}`)
+	other := filepath.Join(t.TempDir(), "other.json")
+
+	if out, code := run(t, addArgs("FromFlag", "--config", other)...); code != 0 {
+		t.Fatalf("add failed (%d): %s", code, out)
+	}
+	if models := loadModels(t, other); len(models) != 1 || models[0].DisplayName != "FromFlag" {
+		t.Errorf("Expected model in flag path, got %+v", models)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := Run(other, []string{"list"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("list failed (%d): %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "FromFlag") {
+		t.Errorf("Expected global config path to be used, got %q", stdout.String())
+	}
+}
+
+func TestUnparseableConfig(t *testing.T) {
+	path := setupConfig(t, "{\n  \"custom_models\": [\n}\n")
+
+	out, code := run(t, "list")
+	if code == 0 {
+		t.Fatal("Expected list to fail on an unparseable file")
+	}
+	if !strings.Contains(out, ":3:1:") || !strings.Contains(out, "^") {
+		t.Errorf("Expected position and context in error, got %q", out)
+	}
+
+	if out, code := run(t, addArgs("A")...); code == 0 {
+		t.Fatalf("Expected add without --force to fail, got %q", out)
+	}
+	if out, code := run(t, addArgs("A", "--force")...); code != 0 {
+		t.Fatalf("add --force failed (%d): %s", code, out)
+	}
+	if models := loadModels(t, path); len(models) != 1 {
+		t.Errorf("Expected the file to be replaced, got %+v", models)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	path := setupConfig(t, "")
+
+	run(t, addArgs("A")...)
+	run(t, addArgs("B")...)
+
+	out, code := run(t, "restore")
+	if code != 0 || !strings.Contains(out, "1. ") {
+		t.Fatalf("Expected a backup listing, got (%d) %q", code, out)
+	}
+
+	out, code = run(t, "restore", "1", "--diff")
+	if code != 0 || !strings.Contains(out, `-       "model_display_name": "B"`) {
+		t.Fatalf("Expected diff removing B, got (%d) %q", code, out)
+	}
+
+	if out, code := run(t, "restore", "1"); code != 0 {
+		t.Fatalf("restore failed (%d): %s", code, out)
+	}
+	if models := loadModels(t, path); len(models) != 1 || models[0].DisplayName != "A" {
+		t.Errorf("Expected only model A after restore, got %+v", models)
+	}
+}
+
+func TestImport(t *testing.T) {
+	path := setupConfig(t, `{"custom_models": [{"model_display_name": "OpenAI gpt-4o", "provider": "openai"}]}`)
+	source := filepath.Join(t.TempDir(), ".env")
+	content := "OPENAI_API_KEY=sk-a\nOPENAI_MODEL=gpt-4o\nGROQ_API_KEY=gsk\nGROQ_BASE_URL=https://api.groq.com/openai/v1\n"
+	if err := os.WriteFile(source, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	out, code := run(t, "import", source)
+	if code != 0 || !strings.Contains(out, "[duplicate of OpenAI gpt-4o]") {
+		t.Fatalf("Expected preview flagging the duplicate, got (%d) %q", code, out)
+	}
+	if models := loadModels(t, path); len(models) != 1 {
+		t.Fatalf("Preview must not write, got %+v", models)
+	}
+
+	if out, code := run(t, "import", source, "--select", "2,2"); code == 0 || !strings.Contains(out, "selected twice") {
+		t.Errorf("Expected a repeated selection to be rejected, got (%d) %q", code, out)
+	}
+	if models := loadModels(t, path); len(models) != 1 {
+		t.Fatalf("Rejected selection must not write, got %+v", models)
+	}
+
+	if out, code := run(t, "import", source, "--all"); code != 0 {
+		t.Fatalf("import --all failed (%d): %s", code, out)
+	}
+	models := loadModels(t, path)
+	if len(models) != 2 || models[1].DisplayName != "Groq" {
+		t.Errorf("Expected only the non-duplicate to be added, got %+v", models)
+	}
+}
+
+func TestImportReplacesKeyReferences(t *testing.T) {
+	path := setupConfig(t, `{"custom_models": []}`)
+	source := filepath.Join(t.TempDir(), "bundle.json")
+	content := `{"custom_models": [{"model_display_name": "Shared", "model": "m", "api_key": "cmd:touch pwned", "provider": "openai"}]}`
+	if err := os.WriteFile(source, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	out, code := run(t, "import", source)
+	if code != 0 || !strings.Contains(out, "[API key cmd:touch pwned]") {
+		t.Fatalf("Expected the preview to show the key command, got (%d) %q", code, out)
+	}
+
+	if out, code := run(t, "import", source, "--all"); code != 0 {
+		t.Fatalf("import --all failed (%d): %s", code, out)
+	}
+	if models := loadModels(t, path); len(models) != 1 || models[0].APIKey != "env:SHARED_API_KEY" {
+		t.Fatalf("Expected the key command replaced with a placeholder, got %+v", models)
+	}
+
+	if out, code := run(t, "import", source, "--all", "--keep-key-refs"); code != 0 {
+		t.Fatalf("import --keep-key-refs failed (%d): %s", code, out)
+	}
+	// The first import makes this one a duplicate, so --all skips it.
+	if out, code := run(t, "import", source, "--select", "1", "--keep-key-refs"); code != 0 {
+		t.Fatalf("import --select failed (%d): %s", code, out)
+	}
+	if models := loadModels(t, path); len(models) != 2 || models[1].APIKey != "cmd:touch pwned" {
+		t.Errorf("Expected the key command kept on request, got %+v", models)
+	}
+}
+
+func TestExport(t *testing.T) {
+	setupConfig(t, `{"custom_models": [
+		{"model_display_name": "A", "api_key": "sk-secret-a", "provider": "openai"},
+		{"model_display_name": "B", "api_key": "sk-secret-b", "provider": "openai"}
+	]}`)
+	output := filepath.Join(t.TempDir(), "bundle.yaml")
+
+	if out, code := run(t, "export", "B", "--output", output); code != 0 {
+		t.Fatalf("export failed (%d): %s", code, out)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(data)
+	if !strings.Contains(out, "custom_models:") || !strings.Contains(out, "env:B_API_KEY") {
+		t.Errorf("Expected YAML bundle with placeholder, got %q", out)
+	}
+	if strings.Contains(out, "sk-secret") || strings.Contains(out, "model_display_name: A") {
+		t.Errorf("Bundle leaked a key or an unselected model: %q", out)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	setupConfig(t, `{"custom_models": [
+		{"model_display_name": "Good", "model": "gpt-4o", "base_url": "https://api.openai.com/v1", "api_key": "sk-test", "provider": "openai"},
+		{"model_display_name": "good", "model": "", "base_url": "api.example.com", "api_key": "", "provider": "mystery"}
+	], "theme": "dark"}`)
+
+	out, code := run(t, "validate")
+	if code == 0 {
+		t.Fatalf("Expected validate to fail, got: %s", out)
+	}
+	for _, want := range []string{
+		"duplicate display name",
+		"model: required",
+		"base_url: missing scheme",
+		"unknown provider \"mystery\"",
+		"no API key set",
+		"unknown top-level key \"theme\"",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected report to contain %q, got: %s", want, out)
+		}
+	}
+
+	out, code = run(t, "doctor", "--json")
+	if code == 0 {
+		t.Fatalf("Expected doctor to fail, got: %s", out)
+	}
+	var rep report
+	if err := json.Unmarshal([]byte(out), &rep); err != nil {
+		t.Fatalf("Invalid JSON report: %v\n%s", err, out)
+	}
+	if rep.Valid || rep.Models != 2 || rep.Errors == 0 || rep.Warnings == 0 {
+		t.Errorf("Unexpected report summary: %+v", rep)
+	}
+}
+
+func TestValidateCleanConfig(t *testing.T) {
+	setupConfig(t, `{"custom_models": [
+		{"model_display_name": "Local", "model": "llama3", "base_url": "http://localhost:11434/v1", "api_key": "env:DROID_TEST_KEY", "provider": "openai"}
+	]}`)
+
+	out, code := run(t, "validate", "--strict")
+	if code == 0 || !strings.Contains(out, "DROID_TEST_KEY is not set") {
+		t.Fatalf("Expected --strict to fail on the unset variable, got %d: %s", code, out)
+	}
+
+	t.Setenv("DROID_TEST_KEY", "x")
+	out, code = run(t, "validate", "--strict")
+	if code != 0 || !strings.Contains(out, "No problems found") {
+		t.Errorf("Expected a clean report, got %d: %s", code, out)
+	}
+}
+
+func TestValidateReportsParseError(t *testing.T) {
+	setupConfig(t, "{\n  \"custom_models\": [\n}")
+
+	out, code := run(t, "validate", "--json")
+	if code == 0 {
+		t.Fatalf("Expected failure, got: %s", out)
+	}
+	var rep report
+	if err := json.Unmarshal([]byte(out), &rep); err != nil {
+		t.Fatalf("Invalid JSON report: %v\n%s", err, out)
+	}
+	if len(rep.Issues) != 1 || rep.Issues[0].Line != 3 {
+		t.Errorf("Expected one issue on line 3, got %+v", rep.Issues)
+	}
+}
+
+func TestWritesWaitForLock(t *testing.T) {
+	path := setupConfig(t, `{"custom_models": []}`)
+
+	lock, err := config.LockFile(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, code := run(t, addArgs("A")...)
+	if code == 0 || !strings.Contains(out, "locked") || !strings.Contains(out, "--wait") {
+		t.Fatalf("Expected add to fail on the lock, got %d: %s", code, out)
+	}
+	if out, code := run(t, "list"); code != 0 {
+		t.Errorf("Expected list to ignore the lock, got %d: %s", code, out)
+	}
+
+	go func() {
+		time.Sleep(150 * time.Millisecond)
+		lock.Unlock()
+	}()
+	if out, code := run(t, addArgs("A", "--wait=5s")...); code != 0 {
+		t.Fatalf("Expected add --wait to succeed, got %d: %s", code, out)
+	}
+	if models := loadModels(t, path); len(models) != 1 {
+		t.Errorf("Expected 1 model, got %+v", models)
+	}
+}
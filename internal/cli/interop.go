@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/diogo/droid-config/internal/interop"
+)
+
+func formatNames() string {
+	names := make([]string, len(interop.Formats))
+	for i, f := range interop.Formats {
+		names[i] = string(f)
+	}
+	return strings.Join(names, ", ")
+}
+
+func runImport(r *runner, args []string) error {
+	fs := r.newFlagSet("import", "<file> [flags]")
+	format := fs.String("format", "", "source format ("+formatNames()+"); detected when empty")
+	all := fs.Bool("all", false, "add every model that is not a duplicate")
+	selection := fs.String("select", "", "comma-separated preview numbers to add, e.g. 1,3")
+	keepRefs := fs.Bool("keep-key-refs", false, "keep file: and cmd: API key references instead of replacing them with env: placeholders")
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, rest, 1); err != nil {
+		return err
+	}
+
+	imported, detected, err := interop.ImportFile(rest[0], interop.Format(*format))
+	if err != nil {
+		return err
+	}
+
+	cfg, path, err := r.loadForUpdate()
+	if err != nil {
+		return err
+	}
+	candidates := interop.Preview(imported, cfg.CustomModels)
+
+	var chosen []int
+	switch {
+	case *selection != "":
+		seen := make(map[int]bool)
+		for _, part := range strings.Split(*selection, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || n < 1 || n > len(candidates) {
+				return fmt.Errorf("invalid selection %q (expected 1-%d)", part, len(candidates))
+			}
+			if seen[n] {
+				return fmt.Errorf("invalid selection: %d is selected twice", n)
+			}
+			seen[n] = true
+			chosen = append(chosen, n-1)
+		}
+	case *all:
+		for i, c := range candidates {
+			if !c.Duplicate() {
+				chosen = append(chosen, i)
+			}
+		}
+	default:
+		fmt.Fprintf(r.stdout, "Found %d model(s) in %s (%s):\n", len(candidates), rest[0], detected)
+		for i, c := range candidates {
+			note := ""
+			if c.Duplicate() {
+				note = "  [duplicate of " + c.DuplicateOf + "]"
+			}
+			if c.KeyRef != "" {
+				note += "  [API key " + c.KeyRef + "]"
+			}
+			fmt.Fprintf(r.stdout, "%d. %s\t%s\t%s\t%s%s\n", i+1, c.Model.DisplayName, c.Model.Provider, c.Model.Model, c.Model.BaseURL, note)
+		}
+		fmt.Fprintln(r.stdout, "\nNothing was added; rerun with --all or --select to import.")
+		if hasKeyRefs(candidates) {
+			fmt.Fprintln(r.stdout, "Keys read from a file or a command are replaced with env: placeholders unless --keep-key-refs is given.")
+		}
+		return nil
+	}
+
+	if len(chosen) == 0 {
+		fmt.Fprintln(r.stdout, "Nothing to import")
+		return nil
+	}
+	for _, i := range chosen {
+		c := candidates[i]
+		m := c.Model
+		if *keepRefs {
+			m = c.KeepKeyRef()
+		}
+		cfg.CustomModels = append(cfg.CustomModels, m)
+		fmt.Fprintf(r.stdout, "Imported %q\n", m.DisplayName)
+		if c.KeyRef != "" && !*keepRefs {
+			fmt.Fprintf(r.stderr, "  API key %q replaced with %s; use --keep-key-refs to keep it\n", c.KeyRef, m.APIKey)
+		}
+	}
+	return r.save(path, cfg)
+}
+
+func hasKeyRefs(candidates []interop.Candidate) bool {
+	for _, c := range candidates {
+		if c.KeyRef != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func runExport(r *runner, args []string) error {
+	fs := r.newFlagSet("export", "[<position|name>...] [flags]")
+	output := fs.String("output", "", "file to write (default: stdout)")
+	format := fs.String("format", "", "bundle format, json or yaml (default: from --output extension, else json)")
+	secrets := fs.String("secrets", string(interop.SecretsEnv), "plain-text API keys: env (replace with env: placeholder), redact or keep")
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	mode := interop.SecretMode(*secrets)
+	if !slices.Contains(interop.SecretModes, mode) {
+		return fmt.Errorf("invalid --secrets %q (expected env, redact or keep)", *secrets)
+	}
+	bundleFormat := interop.ExportFormat(*format)
+	switch {
+	case *format == "":
+		bundleFormat = interop.ExportFormatFor(*output)
+	case bundleFormat != interop.ExportJSON && bundleFormat != interop.ExportYAML:
+		return fmt.Errorf("invalid --format %q (expected json or yaml)", *format)
+	}
+
+	cfg, _, err := r.load()
+	if err != nil {
+		return err
+	}
+
+	models := cfg.CustomModels
+	if len(rest) > 0 {
+		models = nil
+		for _, ref := range rest {
+			idx, err := resolveRef(cfg.CustomModels, ref)
+			if err != nil {
+				return err
+			}
+			models = append(models, cfg.CustomModels[idx])
+		}
+	}
+
+	data, err := interop.Export(models, bundleFormat, mode)
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		_, err := r.stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(*output, data, 0600); err != nil {
+		return err
+	}
+	fmt.Fprintf(r.stderr, "Exported %d model(s) to %s\n", len(models), *output)
+	return nil
+}
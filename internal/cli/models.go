@@ -0,0 +1,378 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/diogo/droid-config/internal/config"
+)
+
+// modelFlags binds one flag per CustomModel field.
+type modelFlags struct {
+	name      string
+	model     string
+	baseURL   string
+	apiKey    string
+	provider  string
+	maxTokens int
+}
+
+func (f *modelFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.name, "name", "", "display name")
+	fs.StringVar(&f.model, "model", "", "model ID sent to the provider")
+	fs.StringVar(&f.baseURL, "base-url", "", "API base URL")
+	fs.StringVar(&f.apiKey, "api-key", "", "API key, or a reference: env:VAR, file:PATH or cmd:COMMAND")
+	fs.StringVar(&f.provider, "provider", "", "provider ("+strings.Join(config.Providers, ", ")+")")
+	fs.IntVar(&f.maxTokens, "max-tokens", 0, "maximum tokens per request")
+}
+
+// apply copies the flags that were explicitly set onto m.
+func (f *modelFlags) apply(fs *flag.FlagSet, m *config.CustomModel) {
+	fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "name":
+			m.DisplayName = f.name
+		case "model":
+			m.Model = f.model
+		case "base-url":
+			m.BaseURL = f.baseURL
+		case "api-key":
+			m.APIKey = f.apiKey
+		case "provider":
+			m.Provider = f.provider
+		case "max-tokens":
+			m.MaxTokens = f.maxTokens
+		}
+	})
+}
+
+// changed reports whether any field flag was set, as opposed to flags every
+// command takes.
+func (f *modelFlags) changed(fs *flag.FlagSet) bool {
+	set := false
+	fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "config", "wait":
+		default:
+			set = true
+		}
+	})
+	return set
+}
+
+// checkModel validates models[idx] against the rest, as the validate
+// command would. Errors fail the command; warnings are only printed.
+func (r *runner) checkModel(models []config.CustomModel, idx int) error {
+	var errs []string
+	for _, issue := range config.ValidateModels(models)[idx] {
+		if issue.Severity == config.SeverityWarning {
+			fmt.Fprintf(r.stderr, "Warning: %s\n", issue)
+			continue
+		}
+		errs = append(errs, issue.String())
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid model: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// resolveRef finds a model by 1-based position or by display name.
+func resolveRef(models []config.CustomModel, ref string) (int, error) {
+	if n, err := strconv.Atoi(ref); err == nil {
+		if n < 1 || n > len(models) {
+			return -1, fmt.Errorf("position %d out of range (1-%d)", n, len(models))
+		}
+		return n - 1, nil
+	}
+
+	for i, m := range models {
+		if m.DisplayName == ref {
+			return i, nil
+		}
+	}
+
+	match := -1
+	for i, m := range models {
+		if strings.EqualFold(m.DisplayName, ref) {
+			if match >= 0 {
+				return -1, fmt.Errorf("model name %q is ambiguous", ref)
+			}
+			match = i
+		}
+	}
+	if match < 0 {
+		return -1, fmt.Errorf("no model named %q", ref)
+	}
+	return match, nil
+}
+
+func runList(r *runner, args []string) error {
+	fs := r.newFlagSet("list", "[flags]")
+	asJSON := fs.Bool("json", false, "print models as JSON")
+	reveal := fs.Bool("reveal", false, "print API keys in clear text")
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, rest, 0); err != nil {
+		return err
+	}
+
+	cfg, _, err := r.load()
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		return writeModelsJSON(r.stdout, cfg.CustomModels, *reveal)
+	}
+
+	if len(cfg.CustomModels) == 0 {
+		fmt.Fprintln(r.stdout, "No models configured")
+		return nil
+	}
+	for i, m := range cfg.CustomModels {
+		fmt.Fprintf(r.stdout, "%d. %s\t%s\t%s\t%s\n", i+1, m.DisplayName, m.Provider, m.Model, m.BaseURL)
+	}
+	return nil
+}
+
+func runShow(r *runner, args []string) error {
+	fs := r.newFlagSet("show", "<position|name> [flags]")
+	asJSON := fs.Bool("json", false, "print the model as JSON")
+	reveal := fs.Bool("reveal", false, "print the API key in clear text")
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, rest, 1); err != nil {
+		return err
+	}
+
+	cfg, _, err := r.load()
+	if err != nil {
+		return err
+	}
+	idx, err := resolveRef(cfg.CustomModels, rest[0])
+	if err != nil {
+		return err
+	}
+
+	m := cfg.CustomModels[idx]
+	if !*reveal {
+		m.APIKey = config.MaskSecret(m.APIKey)
+	}
+	if *asJSON {
+		data, err := json.MarshalIndent(m, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(r.stdout, string(data))
+		return nil
+	}
+
+	fmt.Fprintf(r.stdout, "Position:     %d\n", idx+1)
+	fmt.Fprintf(r.stdout, "Display Name: %s\n", m.DisplayName)
+	fmt.Fprintf(r.stdout, "Model ID:     %s\n", m.Model)
+	fmt.Fprintf(r.stdout, "Base URL:     %s\n", m.BaseURL)
+	fmt.Fprintf(r.stdout, "API Key:      %s\n", m.APIKey)
+	fmt.Fprintf(r.stdout, "Provider:     %s\n", m.Provider)
+	fmt.Fprintf(r.stdout, "Max Tokens:   %d\n", m.MaxTokens)
+	extra := m.Extra()
+	for _, k := range m.ExtraKeys() {
+		fmt.Fprintf(r.stdout, "%-13s %s\n", k+":", extra[k])
+	}
+	return nil
+}
+
+func runAdd(r *runner, args []string) error {
+	fs := r.newFlagSet("add", "--name <name> [flags]")
+	var mf modelFlags
+	mf.register(fs)
+	position := fs.Int("position", 0, "1-based position to insert at (default: append)")
+	fs.BoolVar(&r.force, "force", false, "replace the config file even if it does not parse")
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, rest, 0); err != nil {
+		return err
+	}
+
+	m := config.CustomModel{Provider: "openai"}
+	mf.apply(fs, &m)
+
+	cfg, path, err := r.loadForUpdate()
+	if err != nil {
+		return err
+	}
+
+	idx := len(cfg.CustomModels)
+	if *position != 0 {
+		if *position < 1 || *position > len(cfg.CustomModels)+1 {
+			return fmt.Errorf("position %d out of range (1-%d)", *position, len(cfg.CustomModels)+1)
+		}
+		idx = *position - 1
+	}
+	cfg.CustomModels = append(cfg.CustomModels, config.CustomModel{})
+	copy(cfg.CustomModels[idx+1:], cfg.CustomModels[idx:])
+	cfg.CustomModels[idx] = m
+	if err := r.checkModel(cfg.CustomModels, idx); err != nil {
+		return err
+	}
+
+	if err := r.save(path, cfg); err != nil {
+		return err
+	}
+	fmt.Fprintf(r.stdout, "Added %q at position %d\n", m.DisplayName, idx+1)
+	return nil
+}
+
+func runEdit(r *runner, args []string) error {
+	fs := r.newFlagSet("edit", "<position|name> [flags]")
+	var mf modelFlags
+	mf.register(fs)
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, rest, 1); err != nil {
+		return err
+	}
+	if !mf.changed(fs) {
+		return fmt.Errorf("nothing to change; pass at least one field flag")
+	}
+
+	cfg, path, err := r.loadForUpdate()
+	if err != nil {
+		return err
+	}
+	idx, err := resolveRef(cfg.CustomModels, rest[0])
+	if err != nil {
+		return err
+	}
+
+	m := cfg.CustomModels[idx]
+	mf.apply(fs, &m)
+	cfg.CustomModels[idx] = m
+	if err := r.checkModel(cfg.CustomModels, idx); err != nil {
+		return err
+	}
+
+	if err := r.save(path, cfg); err != nil {
+		return err
+	}
+	fmt.Fprintf(r.stdout, "Updated %q\n", m.DisplayName)
+	return nil
+}
+
+func runRemove(r *runner, args []string) error {
+	fs := r.newFlagSet("remove", "<position|name>...")
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) == 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	cfg, path, err := r.loadForUpdate()
+	if err != nil {
+		return err
+	}
+
+	// Resolve every reference against the original order before deleting.
+	remove := make(map[int]bool)
+	for _, ref := range rest {
+		idx, err := resolveRef(cfg.CustomModels, ref)
+		if err != nil {
+			return err
+		}
+		remove[idx] = true
+	}
+
+	kept := make([]config.CustomModel, 0, len(cfg.CustomModels)-len(remove))
+	for i, m := range cfg.CustomModels {
+		if remove[i] {
+			fmt.Fprintf(r.stdout, "Removed %q\n", m.DisplayName)
+			continue
+		}
+		kept = append(kept, m)
+	}
+	cfg.CustomModels = kept
+
+	return r.save(path, cfg)
+}
+
+func runMove(r *runner, args []string) error {
+	fs := r.newFlagSet("move", "<position|name> <position|up|down|top|bottom>")
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, rest, 2); err != nil {
+		return err
+	}
+
+	cfg, path, err := r.loadForUpdate()
+	if err != nil {
+		return err
+	}
+	from, err := resolveRef(cfg.CustomModels, rest[0])
+	if err != nil {
+		return err
+	}
+
+	last := len(cfg.CustomModels) - 1
+	var to int
+	switch rest[1] {
+	case "up":
+		to = max(0, from-1)
+	case "down":
+		to = min(last, from+1)
+	case "top":
+		to = 0
+	case "bottom":
+		to = last
+	default:
+		n, err := strconv.Atoi(rest[1])
+		if err != nil || n < 1 || n > last+1 {
+			return fmt.Errorf("invalid target position %q (expected 1-%d, up, down, top or bottom)", rest[1], last+1)
+		}
+		to = n - 1
+	}
+
+	m := cfg.CustomModels[from]
+	if from != to {
+		models := append(cfg.CustomModels[:from:from], cfg.CustomModels[from+1:]...)
+		models = append(models[:to], append([]config.CustomModel{m}, models[to:]...)...)
+		cfg.CustomModels = models
+		if err := r.save(path, cfg); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(r.stdout, "Moved %q to position %d\n", m.DisplayName, to+1)
+	return nil
+}
+
+func writeModelsJSON(w io.Writer, models []config.CustomModel, reveal bool) error {
+	out := make([]config.CustomModel, len(models))
+	for i, m := range models {
+		if !reveal {
+			m.APIKey = config.MaskSecret(m.APIKey)
+		}
+		out[i] = m
+	}
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, string(data))
+	return nil
+}
@@ -0,0 +1,179 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/diogo/droid-config/internal/config"
+)
+
+// report is the result of validating a config file, also printed as JSON.
+type report struct {
+	Path     string        `json:"path"`
+	Models   int           `json:"models"`
+	Valid    bool          `json:"valid"`
+	Errors   int           `json:"errors"`
+	Warnings int           `json:"warnings"`
+	Issues   []reportIssue `json:"issues"`
+}
+
+// reportIssue is one finding; Model is the 1-based position of the model it
+// concerns, or 0 for problems with the file itself.
+type reportIssue struct {
+	Model    int    `json:"model,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Field    string `json:"field,omitempty"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+}
+
+func (rep *report) add(issue reportIssue) {
+	if issue.Severity == config.SeverityError.String() {
+		rep.Errors++
+	} else {
+		rep.Warnings++
+	}
+	rep.Issues = append(rep.Issues, issue)
+}
+
+func runValidate(r *runner, args []string) error {
+	fs := r.newFlagSet("validate", "[flags]")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	strict := fs.Bool("strict", false, "treat warnings as errors for the exit code")
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, rest, 0); err != nil {
+		return err
+	}
+
+	path, err := config.ResolvePath(r.configPath)
+	if err != nil {
+		return err
+	}
+	rep, parseErr := validateFile(path)
+
+	if *asJSON {
+		enc := json.NewEncoder(r.stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(rep); err != nil {
+			return err
+		}
+	} else {
+		printReport(r.stdout, rep, parseErr)
+	}
+
+	if rep.Errors > 0 || (*strict && rep.Warnings > 0) {
+		return errReported
+	}
+	return nil
+}
+
+// validateFile loads path and collects every problem with it. The parse
+// error, if any, is returned as well so that its context can be shown.
+func validateFile(path string) (*report, *config.ParseError) {
+	rep := &report{Path: path, Issues: []reportIssue{}}
+	fail := func(format string, args ...any) {
+		rep.add(reportIssue{Severity: config.SeverityError.String(), Message: fmt.Sprintf(format, args...)})
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			fail("file does not exist")
+		} else {
+			fail("%v", err)
+		}
+		return rep, nil
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		var parseErr *config.ParseError
+		if errors.As(err, &parseErr) {
+			rep.add(reportIssue{
+				Severity: config.SeverityError.String(),
+				Message:  parseErr.Err.Error(),
+				Line:     parseErr.Line,
+				Column:   parseErr.Column,
+			})
+			return rep, parseErr
+		}
+		fail("%v", err)
+		return rep, nil
+	}
+
+	for _, key := range cfg.ExtraKeys() {
+		rep.add(reportIssue{
+			Severity: config.SeverityWarning.String(),
+			Message:  fmt.Sprintf("unknown top-level key %q (kept as is)", key),
+		})
+	}
+
+	rep.Models = len(cfg.CustomModels)
+	for i, issues := range config.ValidateModels(cfg.CustomModels) {
+		m := cfg.CustomModels[i]
+		if kind, ref := config.ParseSecretRef(m.APIKey); kind == config.SecretEnv {
+			if _, ok := os.LookupEnv(ref); !ok {
+				issues = append(issues, config.Issue{
+					Field:    config.FieldAPIKey,
+					Severity: config.SeverityWarning,
+					Message:  fmt.Sprintf("environment variable %s is not set", ref),
+				})
+			}
+		}
+		for _, issue := range issues {
+			rep.add(reportIssue{
+				Model:    i + 1,
+				Name:     m.DisplayName,
+				Field:    string(issue.Field),
+				Severity: issue.Severity.String(),
+				Message:  issue.Message,
+			})
+		}
+	}
+
+	rep.Valid = rep.Errors == 0
+	return rep, nil
+}
+
+func printReport(w io.Writer, rep *report, parseErr *config.ParseError) {
+	fmt.Fprintf(w, "%s: %d model(s)\n", config.DisplayPath(rep.Path), rep.Models)
+
+	lastModel := -1
+	for _, issue := range rep.Issues {
+		if issue.Model != lastModel {
+			if issue.Model == 0 {
+				fmt.Fprintln(w, "  file:")
+			} else {
+				fmt.Fprintf(w, "  %d. %s:\n", issue.Model, issue.Name)
+			}
+			lastModel = issue.Model
+		}
+		text := issue.Message
+		if issue.Field != "" {
+			text = issue.Field + ": " + text
+		}
+		if issue.Line > 0 {
+			text = fmt.Sprintf("line %d, column %d: %s", issue.Line, issue.Column, text)
+		}
+		fmt.Fprintf(w, "    %-7s %s\n", issue.Severity, text)
+	}
+	if parseErr != nil {
+		fmt.Fprintln(w)
+		for _, line := range parseErr.Context(2) {
+			fmt.Fprintln(w, "    "+line)
+		}
+	}
+
+	if len(rep.Issues) == 0 {
+		fmt.Fprintln(w, "No problems found")
+		return
+	}
+	fmt.Fprintf(w, "%d error(s), %d warning(s)\n", rep.Errors, rep.Warnings)
+}
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultBackupCount is the number of backups kept when nothing else is configured.
+const DefaultBackupCount = 10
+
+// EnvBackupCount names the environment variable that sets how many backups to keep.
+const EnvBackupCount = "DROID_CONFIG_BACKUPS"
+
+const backupTimeFormat = "20060102-150405.000"
+
+// Backup is a timestamped copy of the config file taken before a write.
+type Backup struct {
+	Path string
+	Time time.Time
+	Size int64
+}
+
+// Name returns the backup's file name.
+func (b Backup) Name() string {
+	return filepath.Base(b.Path)
+}
+
+// BackupDir returns the directory holding backups of the config at path,
+// e.g. ~/.factory/backups for ~/.factory/config.json.
+func BackupDir(path string) string {
+	return filepath.Join(filepath.Dir(path), "backups")
+}
+
+func (o SaveOptions) backupLimit() int {
+	if o.Backups != 0 {
+		return max(0, o.Backups)
+	}
+	if v := os.Getenv(EnvBackupCount); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			return max(0, n)
+		}
+	}
+	return DefaultBackupCount
+}
+
+// backupName splits the config file name into the parts used for its backups:
+// config.json is backed up as config-<timestamp>.json.
+func backupName(path string) (prefix, ext string) {
+	base := filepath.Base(path)
+	ext = filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + "-", ext
+}
+
+// ListBackups returns the backups of the config at path, newest first.
+func ListBackups(path string) ([]Backup, error) {
+	entries, err := os.ReadDir(BackupDir(path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	prefix, ext := backupName(path)
+	var backups []Backup
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+		// Strip the collision counter added when two backups share a timestamp.
+		if i := strings.LastIndexByte(stamp, '_'); i >= 0 {
+			stamp = stamp[:i]
+		}
+		t, err := time.ParseInLocation(backupTimeFormat, stamp, time.Local)
+		if err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, Backup{
+			Path: filepath.Join(BackupDir(path), name),
+			Time: t,
+			Size: info.Size(),
+		})
+	}
+
+	sort.SliceStable(backups, func(i, j int) bool {
+		if backups[i].Time.Equal(backups[j].Time) {
+			return backups[i].Path > backups[j].Path
+		}
+		return backups[i].Time.After(backups[j].Time)
+	})
+	return backups, nil
+}
+
+// backupFile copies the current config at path into the backup directory and
+// prunes old backups so that at most keep remain. A missing file is not an error.
+func backupFile(path string, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	dir := BackupDir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	prefix, ext := backupName(path)
+	stamp := time.Now().Format(backupTimeFormat)
+	target := filepath.Join(dir, prefix+stamp+ext)
+	for n := 1; ; n++ {
+		if _, err := os.Stat(target); os.IsNotExist(err) {
+			break
+		}
+		target = filepath.Join(dir, fmt.Sprintf("%s%s_%d%s", prefix, stamp, n, ext))
+	}
+
+	if err := os.WriteFile(target, data, 0600); err != nil {
+		return err
+	}
+
+	backups, err := ListBackups(path)
+	if err != nil {
+		return err
+	}
+	for _, b := range backups[min(keep, len(backups)):] {
+		if err := os.Remove(b.Path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// RestoreBackup replaces the config at path with the contents of b. The
+// current file is backed up first, so a restore can itself be undone.
+func RestoreBackup(path string, b Backup, opts SaveOptions) error {
+	if filepath.Dir(b.Path) != BackupDir(path) {
+		return errors.New("backup does not belong to this config file")
+	}
+
+	data, err := os.ReadFile(b.Path)
+	if err != nil {
+		return err
+	}
+	if _, err := parse(b.Path, data); err != nil {
+		return err
+	}
+
+	return opts.withLock(path, func() error {
+		return writeFile(path, data, opts)
+	})
+}
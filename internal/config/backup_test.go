@@ -0,0 +1,97 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSaveCreatesAndPrunesBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ConfigFileName)
+	opts := SaveOptions{Backups: 2}
+
+	for _, name := range []string{"A", "B", "C", "D"} {
+		cfg := &ConfigData{CustomModels: []CustomModel{{DisplayName: name}}}
+		if err := SaveWith(path, cfg, opts); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+
+	backups, err := ListBackups(path)
+	if err != nil {
+		t.Fatalf("ListBackups failed: %v", err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("Expected 2 backups, got %d", len(backups))
+	}
+	if !strings.HasPrefix(backups[0].Name(), "config-") {
+		t.Errorf("Unexpected backup name %s", backups[0].Name())
+	}
+
+	// The newest backup holds the state before the last save.
+	data, _ := os.ReadFile(backups[0].Path)
+	if !strings.Contains(string(data), `"C"`) {
+		t.Errorf("Expected newest backup to contain model C, got %s", data)
+	}
+}
+
+func TestBackupsDisabled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ConfigFileName)
+	t.Setenv(EnvBackupCount, "0")
+
+	for i := 0; i < 2; i++ {
+		if err := Save(path, &ConfigData{}); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+	if _, err := os.Stat(BackupDir(path)); !os.IsNotExist(err) {
+		t.Error("Expected no backup directory when backups are disabled")
+	}
+}
+
+func TestRestoreBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ConfigFileName)
+
+	if err := Save(path, &ConfigData{CustomModels: []CustomModel{{DisplayName: "Old"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Save(path, &ConfigData{CustomModels: []CustomModel{{DisplayName: "New"}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	backups, _ := ListBackups(path)
+	if len(backups) != 1 {
+		t.Fatalf("Expected 1 backup, got %d", len(backups))
+	}
+	if err := RestoreBackup(path, backups[0], SaveOptions{}); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.CustomModels[0].DisplayName != "Old" {
+		t.Errorf("Expected restored model Old, got %s", cfg.CustomModels[0].DisplayName)
+	}
+	if backups, _ := ListBackups(path); len(backups) != 2 {
+		t.Errorf("Expected the replaced file to be backed up, got %d backups", len(backups))
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	diff := DiffLines("a\nb\nc\n", "a\nc\nd\n")
+
+	var got []string
+	for _, d := range diff {
+		got = append(got, d.String())
+	}
+	want := []string{"  a", "- b", "  c", "+ d"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if !HasChanges(diff) || HasChanges(DiffLines("x", "x")) {
+		t.Error("HasChanges reported the wrong result")
+	}
+}
@@ -0,0 +1,86 @@
+package config
+
+import "strings"
+
+// DiffOp marks how a line differs between two texts.
+type DiffOp byte
+
+const (
+	DiffEqual  DiffOp = ' '
+	DiffInsert DiffOp = '+'
+	DiffDelete DiffOp = '-'
+)
+
+// DiffLine is one line of a line-based diff.
+type DiffLine struct {
+	Op   DiffOp
+	Text string
+}
+
+func (d DiffLine) String() string {
+	return string(d.Op) + " " + d.Text
+}
+
+// DiffLines computes a minimal line diff turning a into b using the longest
+// common subsequence. Config files are small, so the quadratic table is fine.
+func DiffLines(a, b string) []DiffLine {
+	x := splitLines(a)
+	y := splitLines(b)
+
+	// lcs[i][j] is the LCS length of x[i:] and y[j:].
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out []DiffLine
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] == y[j]:
+			out = append(out, DiffLine{DiffEqual, x[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, DiffLine{DiffDelete, x[i]})
+			i++
+		default:
+			out = append(out, DiffLine{DiffInsert, y[j]})
+			j++
+		}
+	}
+	for ; i < len(x); i++ {
+		out = append(out, DiffLine{DiffDelete, x[i]})
+	}
+	for ; j < len(y); j++ {
+		out = append(out, DiffLine{DiffInsert, y[j]})
+	}
+	return out
+}
+
+// HasChanges reports whether a diff contains any insertions or deletions.
+func HasChanges(diff []DiffLine) bool {
+	for _, d := range diff {
+		if d.Op != DiffEqual {
+			return true
+		}
+	}
+	return false
+}
+
+func splitLines(s string) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
@@ -0,0 +1,263 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+const customModelsKey = "custom_models"
+
+// defaultIndent indents files written from scratch.
+const defaultIndent = "  "
+
+// span is the byte range of a value within a document.
+type span struct {
+	start, end int
+}
+
+// source remembers a parsed document so that encoding can leave everything
+// the user did not change byte-for-byte intact.
+type source struct {
+	data     []byte
+	object   span            // the top-level object, braces included
+	values   map[string]span // each top-level value
+	order    []string        // top-level keys in document order
+	indent   string          // indentation of top-level members; "" when compact
+	original map[string]json.RawMessage
+	models   []CustomModel // custom_models as read
+}
+
+// indexSource records the layout of the top-level object in data.
+func indexSource(data []byte) (*source, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '{' {
+		return nil, fmt.Errorf("config is not a JSON object")
+	}
+
+	open := int(dec.InputOffset())
+	src := &source{
+		data:     data,
+		object:   span{start: open - 1},
+		values:   make(map[string]span),
+		original: make(map[string]json.RawMessage),
+		indent:   memberIndent(data[open:]),
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := tok.(string)
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		end := int(dec.InputOffset())
+		if _, seen := src.values[key]; !seen {
+			src.order = append(src.order, key)
+		}
+		src.values[key] = span{start: end - len(raw), end: end}
+		src.original[key] = raw
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	src.object.end = int(dec.InputOffset())
+	return src, nil
+}
+
+// memberIndent returns the indentation before the first member, or "" when
+// the object is written on one line.
+func memberIndent(rest []byte) string {
+	ws := rest[:len(rest)-len(bytes.TrimLeft(rest, " \t\r\n"))]
+	i := bytes.LastIndexByte(ws, '\n')
+	if i < 0 {
+		return ""
+	}
+	return string(ws[i+1:])
+}
+
+// encode renders the config. When it was parsed from a document, keys keep
+// their order and unchanged values keep their exact text, so only the parts
+// that changed differ from the original.
+func (c *ConfigData) encode() ([]byte, error) {
+	models := c.CustomModels
+	if models == nil {
+		models = []CustomModel{}
+	}
+
+	src := c.source
+	if src == nil {
+		src = &source{indent: defaultIndent}
+	}
+
+	keys := c.keyOrder()
+	value := func(key string) ([]byte, error) {
+		if key == customModelsKey {
+			if c.source != nil && equalModels(models, src.models) {
+				if s, ok := src.values[key]; ok {
+					return src.data[s.start:s.end], nil
+				}
+			}
+			return formatValue(models, src.indent)
+		}
+		raw := c.extra[key]
+		if orig, ok := src.original[key]; ok && bytes.Equal(orig, raw) {
+			s := src.values[key]
+			return src.data[s.start:s.end], nil
+		}
+		return formatRaw(raw, src.indent)
+	}
+
+	if c.source == nil || !equalKeys(keys, src.order) {
+		return c.rebuild(keys, value)
+	}
+
+	// Same keys in the same order: replace only the values that changed,
+	// back to front so earlier offsets stay valid.
+	out := append([]byte(nil), src.data...)
+	for i := len(keys) - 1; i >= 0; i-- {
+		v, err := value(keys[i])
+		if err != nil {
+			return nil, err
+		}
+		s := src.values[keys[i]]
+		if bytes.Equal(v, src.data[s.start:s.end]) {
+			continue
+		}
+		out = append(out[:s.start], append(v, src.data[s.end:]...)...)
+	}
+	return out, nil
+}
+
+// rebuild writes the top-level object anew, in the order of keys, reusing
+// the original text around the object and of unchanged values.
+func (c *ConfigData) rebuild(keys []string, value func(string) ([]byte, error)) ([]byte, error) {
+	src := c.source
+	indent := defaultIndent
+	if src != nil {
+		indent = src.indent
+	}
+
+	var b bytes.Buffer
+	if src != nil {
+		b.Write(src.data[:src.object.start])
+	}
+	b.WriteByte('{')
+	for i, key := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		if indent != "" {
+			b.WriteString("\n" + indent)
+		}
+		name, _ := json.Marshal(key)
+		b.Write(name)
+		b.WriteByte(':')
+		if indent != "" {
+			b.WriteByte(' ')
+		}
+		v, err := value(key)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(v)
+	}
+	if indent != "" {
+		b.WriteByte('\n')
+	}
+	b.WriteByte('}')
+	if src != nil {
+		b.Write(src.data[src.object.end:])
+	} else {
+		b.WriteByte('\n')
+	}
+	return b.Bytes(), nil
+}
+
+// keyOrder lists the keys to write: the original ones still present, in
+// their order, then new extra keys sorted, then custom_models if it is new.
+func (c *ConfigData) keyOrder() []string {
+	var keys []string
+	seen := make(map[string]bool)
+	if c.source != nil {
+		for _, k := range c.source.order {
+			if _, ok := c.extra[k]; ok || k == customModelsKey {
+				keys = append(keys, k)
+				seen[k] = true
+			}
+		}
+	}
+	var added []string
+	for k := range c.extra {
+		if !seen[k] && k != customModelsKey {
+			added = append(added, k)
+		}
+	}
+	sort.Strings(added)
+	keys = append(keys, added...)
+	if !seen[customModelsKey] {
+		keys = append(keys, customModelsKey)
+	}
+	return keys
+}
+
+// formatValue encodes v as a member value nested one level in an object
+// indented with indent.
+func formatValue(v any, indent string) ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if indent != "" {
+		enc.SetIndent(indent, indent)
+	}
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(b.Bytes(), "\n"), nil
+}
+
+func formatRaw(raw json.RawMessage, indent string) ([]byte, error) {
+	var b bytes.Buffer
+	var err error
+	if indent != "" {
+		err = json.Indent(&b, raw, indent, indent)
+	} else {
+		err = json.Compact(&b, raw)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func equalModels(a, b []CustomModel) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalKeys(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrRefuseOverwrite is returned by Save when the file on disk does not parse
+// and SaveOptions.Force was not set, so a broken but recoverable file is not
+// replaced by one containing only custom_models.
+var ErrRefuseOverwrite = errors.New("refusing to overwrite config file that failed to parse")
+
+// ParseError describes a config file whose contents are not valid JSON or do
+// not match the expected shape.
+type ParseError struct {
+	Path   string
+	Line   int // 1-based
+	Column int // 1-based
+	Offset int64
+	Data   []byte
+	Err    error
+}
+
+func newParseError(path string, data []byte, err error) *ParseError {
+	var offset int64
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	default:
+		offset = int64(len(data))
+	}
+
+	line, col := lineColumn(data, offset)
+	return &ParseError{
+		Path:   path,
+		Line:   line,
+		Column: col,
+		Offset: offset,
+		Data:   data,
+		Err:    err,
+	}
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %v", e.Path, e.Line, e.Column, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Context returns up to radius lines on either side of the offending line,
+// each prefixed with its line number, followed by a caret under the column.
+func (e *ParseError) Context(radius int) []string {
+	lines := strings.Split(string(e.Data), "\n")
+	if e.Line < 1 || e.Line > len(lines) {
+		return nil
+	}
+
+	first := max(1, e.Line-radius)
+	last := min(len(lines), e.Line+radius)
+	width := len(fmt.Sprint(last))
+
+	var out []string
+	for n := first; n <= last; n++ {
+		text := strings.ReplaceAll(lines[n-1], "\t", " ")
+		out = append(out, fmt.Sprintf("%*d | %s", width, n, strings.TrimRight(text, "\r")))
+		if n == e.Line {
+			out = append(out, strings.Repeat(" ", width)+" | "+strings.Repeat(" ", max(0, e.Column-1))+"^")
+		}
+	}
+	return out
+}
+
+// lineColumn converts a byte offset into 1-based line and column numbers.
+// The JSON decoder reports the offset just past the offending byte.
+func lineColumn(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	if offset > 0 {
+		offset--
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := int(offset) - bytes.LastIndexByte(before, '\n')
+	return line, col
+}
@@ -1,14 +1,23 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 )
 
 const ConfigFileName = "config.json"
 
-func GetConfigPath() (string, error) {
+// EnvConfigPath names the environment variable that overrides the config location.
+const EnvConfigPath = "DROID_CONFIG_PATH"
+
+// DefaultConfigPath returns the Factory config location, ~/.factory/config.json.
+func DefaultConfigPath() (string, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return "", err
@@ -16,12 +25,48 @@ func GetConfigPath() (string, error) {
 	return filepath.Join(home, ".factory", ConfigFileName), nil
 }
 
-func Load() (*ConfigData, error) {
-	path, err := GetConfigPath()
-	if err != nil {
-		return nil, err
+// GetConfigPath returns $DROID_CONFIG_PATH when set, otherwise the default path.
+func GetConfigPath() (string, error) {
+	return ResolvePath("")
+}
+
+// ResolvePath picks the config file to use. An explicit override (usually the
+// --config flag) wins over $DROID_CONFIG_PATH, which wins over the default.
+// A leading "~/" is expanded to the user's home directory.
+func ResolvePath(override string) (string, error) {
+	path := override
+	if path == "" {
+		path = os.Getenv(EnvConfigPath)
+	}
+	if path == "" {
+		return DefaultConfigPath()
+	}
+
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(home, path[1:])
 	}
+	return filepath.Abs(path)
+}
 
+// DisplayPath shortens path for display by replacing the home directory with "~".
+func DisplayPath(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return path
+	}
+	if rel, err := filepath.Rel(home, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.Join("~", rel)
+	}
+	return path
+}
+
+// Load reads the config file at path. A missing or empty file yields an empty
+// config; contents that do not parse yield a *ParseError.
+func Load(path string) (*ConfigData, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -29,34 +74,118 @@ func Load() (*ConfigData, error) {
 		}
 		return nil, err
 	}
+	return parse(path, data)
+}
+
+func parse(path string, data []byte) (*ConfigData, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return &ConfigData{CustomModels: []CustomModel{}}, nil
+	}
 
 	var cfg ConfigData
 	if err := json.Unmarshal(data, &cfg); err != nil {
-		return &ConfigData{CustomModels: []CustomModel{}}, nil
+		return nil, newParseError(path, data, err)
+	}
+	// Index the whole file, including the whitespace around the object.
+	if err := cfg.setSource(data); err != nil {
+		return nil, newParseError(path, data, err)
+	}
+	if cfg.CustomModels == nil {
+		cfg.CustomModels = []CustomModel{}
 	}
 
 	return &cfg, nil
 }
 
-func Save(cfg *ConfigData) error {
-	path, err := GetConfigPath()
+// SaveOptions tunes how Save writes the config file.
+type SaveOptions struct {
+	// Force overwrites the file even when its current contents do not parse.
+	Force bool
+	// Backups is the number of backups to keep. Zero uses $DROID_CONFIG_BACKUPS
+	// or DefaultBackupCount; a negative value disables backups.
+	Backups int
+	// Wait is how long to wait for another process to release the lock.
+	// Zero fails immediately with ErrLocked.
+	Wait time.Duration
+	// Locked means the caller already holds the lock from LockFile, for
+	// example to cover a whole load-modify-save cycle.
+	Locked bool
+}
+
+// withLock runs fn while holding the lock for path, unless the caller
+// already does.
+func (o SaveOptions) withLock(path string, fn func() error) error {
+	if o.Locked {
+		return fn()
+	}
+	lock, err := LockFile(path, o.Wait)
 	if err != nil {
 		return err
 	}
+	defer lock.Unlock()
+	return fn()
+}
 
-	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+// Save writes cfg to path, refusing to replace a file that does not parse.
+func Save(path string, cfg *ConfigData) error {
+	return SaveWith(path, cfg, SaveOptions{})
+}
+
+// SaveWith writes cfg to path atomically using the given options, backing up
+// the previous contents first.
+func SaveWith(path string, cfg *ConfigData, opts SaveOptions) error {
+	data, err := cfg.encode()
+	if err != nil {
 		return err
 	}
 
-	data, err := json.MarshalIndent(cfg, "", "  ")
+	err = opts.withLock(path, func() error {
+		if !opts.Force {
+			if _, err := Load(path); err != nil {
+				var parseErr *ParseError
+				if errors.As(err, &parseErr) {
+					return fmt.Errorf("%w: %v", ErrRefuseOverwrite, parseErr)
+				}
+				return err
+			}
+		}
+		return writeFile(path, data, opts)
+	})
 	if err != nil {
 		return err
 	}
+	// Later saves compare against what is now on disk.
+	return cfg.setSource(data)
+}
 
-	tmpFile := path + ".tmp"
-	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+func writeFile(path string, data []byte, opts SaveOptions) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 		return err
 	}
 
-	return os.Rename(tmpFile, path)
+	if err := backupFile(path, opts.backupLimit()); err != nil {
+		return fmt.Errorf("backup failed: %w", err)
+	}
+
+	// A unique name keeps concurrent writers from sharing a temp file;
+	// CreateTemp also makes it private to the owner, which matters since the
+	// file may hold plain-text API keys.
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
 }
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResolvePath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(EnvConfigPath, "")
+
+	path, err := ResolvePath("")
+	if err != nil {
+		t.Fatalf("ResolvePath failed: %v", err)
+	}
+	if want := filepath.Join(home, ".factory", ConfigFileName); path != want {
+		t.Errorf("Expected default path %s, got %s", want, path)
+	}
+
+	envPath := filepath.Join(home, "env.json")
+	t.Setenv(EnvConfigPath, envPath)
+	if path, _ := ResolvePath(""); path != envPath {
+		t.Errorf("Expected env path %s, got %s", envPath, path)
+	}
+
+	if path, _ := ResolvePath("~/profile.json"); path != filepath.Join(home, "profile.json") {
+		t.Errorf("Expected flag to override env and expand ~, got %s", path)
+	}
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", ConfigFileName)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load of missing file failed: %v", err)
+	}
+	cfg.CustomModels = append(cfg.CustomModels, CustomModel{DisplayName: "A", Provider: "openai"})
+	if err := Save(path, cfg); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Error("Temporary file was left behind")
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(loaded.CustomModels) != 1 || loaded.CustomModels[0].DisplayName != "A" {
+		t.Errorf("Unexpected models after round trip: %+v", loaded.CustomModels)
+	}
+}
+
+func TestLoadReportsParseError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ConfigFileName)
+	content := "{\n  \"custom_models\": [],\n  \"broken\": tru\n}\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if cfg != nil {
+		t.Error("Expected no config for an unparseable file")
+	}
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected *ParseError, got %v", err)
+	}
+	if parseErr.Line != 3 || parseErr.Column != 16 {
+		t.Errorf("Expected error at 3:16, got %d:%d", parseErr.Line, parseErr.Column)
+	}
+
+	ctx := parseErr.Context(1)
+	if len(ctx) != 4 || !strings.HasPrefix(ctx[1], `3 |   "broken": tru`) || !strings.HasSuffix(ctx[2], "^") {
+		t.Errorf("Unexpected context: %q", ctx)
+	}
+}
+
+func TestSaveRefusesUnparseableFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ConfigFileName)
+	if err := os.WriteFile(path, []byte(`{"keep": 1,`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &ConfigData{CustomModels: []CustomModel{{DisplayName: "A"}}}
+	if err := Save(path, cfg); !errors.Is(err, ErrRefuseOverwrite) {
+		t.Fatalf("Expected ErrRefuseOverwrite, got %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != `{"keep": 1,` {
+		t.Errorf("File was modified: %q", data)
+	}
+
+	if err := SaveWith(path, cfg, SaveOptions{Force: true}); err != nil {
+		t.Fatalf("Forced save failed: %v", err)
+	}
+	if _, err := Load(path); err != nil {
+		t.Errorf("Forced save left an unparseable file: %v", err)
+	}
+}
+
+func TestSaveRespectsLock(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ConfigFileName)
+	cfg := &ConfigData{CustomModels: []CustomModel{{DisplayName: "A"}}}
+
+	lock, err := LockFile(path, 0)
+	if err != nil {
+		t.Fatalf("Failed to lock: %v", err)
+	}
+	if _, err := LockFile(path, 0); !errors.Is(err, ErrLocked) {
+		t.Fatalf("Expected ErrLocked for a second lock, got %v", err)
+	}
+	if err := Save(path, cfg); !errors.Is(err, ErrLocked) {
+		t.Fatalf("Expected ErrLocked while locked, got %v", err)
+	}
+	if err := SaveWith(path, cfg, SaveOptions{Locked: true}); err != nil {
+		t.Fatalf("Save by the lock holder failed: %v", err)
+	}
+
+	go func() {
+		time.Sleep(150 * time.Millisecond)
+		lock.Unlock()
+	}()
+	if err := SaveWith(path, cfg, SaveOptions{Wait: 5 * time.Second}); err != nil {
+		t.Fatalf("Expected the save to wait for the lock, got %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if strings.HasSuffix(e.Name(), ".tmp") {
+			t.Errorf("Temporary file left behind: %s", e.Name())
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// KeysFileName is the name of the key bindings file in KeysDir.
+const KeysFileName = "keys.json"
+
+// KeysDir returns the directory holding droid-config's own settings, such as
+// key bindings, e.g. ~/.config/droid-config on Linux.
+func KeysDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "droid-config"), nil
+}
+
+// KeysPath returns the location of the key bindings file.
+func KeysPath() (string, error) {
+	dir, err := KeysDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, KeysFileName), nil
+}
+
+// KeyBindings is the content of the key bindings file: an optional preset
+// and the keys of single actions, which replace the preset's. An action bound
+// to no keys is disabled.
+//
+//	{
+//	  "preset": "vim",
+//	  "bindings": {"delete": ["d", "x"], "quit": "ctrl+q"}
+//	}
+type KeyBindings struct {
+	Preset   string              `json:"preset,omitempty"`
+	Bindings map[string]KeyNames `json:"bindings,omitempty"`
+}
+
+// KeyNames lists the keys bound to an action. In the file it is either a
+// list or, for a single key, a string.
+type KeyNames []string
+
+func (k *KeyNames) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*k = KeyNames{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return errors.New("expected a key or a list of keys")
+	}
+	*k = many
+	return nil
+}
+
+// LoadKeyBindings reads the key bindings file at path. A missing or empty
+// file yields no bindings.
+func LoadKeyBindings(path string) (KeyBindings, error) {
+	var kb KeyBindings
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return kb, nil
+	}
+	if err != nil {
+		return kb, err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return kb, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&kb); err != nil {
+		return KeyBindings{}, fmt.Errorf("invalid key bindings: %w", err)
+	}
+	for action, keys := range kb.Bindings {
+		for _, k := range keys {
+			if k == "" {
+				return KeyBindings{}, fmt.Errorf("invalid key bindings: empty key for %q", action)
+			}
+		}
+	}
+	return kb, nil
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadKeyBindings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), KeysFileName)
+
+	kb, err := LoadKeyBindings(path)
+	if err != nil {
+		t.Fatalf("Expected a missing file to be fine, got %v", err)
+	}
+	if kb.Preset != "" || len(kb.Bindings) != 0 {
+		t.Errorf("Expected no bindings for a missing file, got %+v", kb)
+	}
+
+	data := `{"preset": "vim", "bindings": {"delete": ["d", "x"], "quit": "ctrl+q", "search": []}}`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	kb, err = LoadKeyBindings(path)
+	if err != nil {
+		t.Fatalf("LoadKeyBindings failed: %v", err)
+	}
+	if kb.Preset != "vim" {
+		t.Errorf("Expected preset vim, got %q", kb.Preset)
+	}
+	want := map[string]KeyNames{
+		"delete": {"d", "x"},
+		"quit":   {"ctrl+q"},
+		"search": {},
+	}
+	if !reflect.DeepEqual(kb.Bindings, want) {
+		t.Errorf("Expected bindings %v, got %v", want, kb.Bindings)
+	}
+}
+
+func TestLoadKeyBindingsRejectsBadFiles(t *testing.T) {
+	tests := map[string]string{
+		"not json":      `{"preset": `,
+		"unknown field": `{"presets": "vim"}`,
+		"bad keys":      `{"bindings": {"quit": 1}}`,
+		"empty key":     `{"bindings": {"quit": ["ctrl+c", ""]}}`,
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), KeysFileName)
+			if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadKeyBindings(path)
+			if err == nil || !strings.Contains(err.Error(), "invalid key bindings") {
+				t.Errorf("Expected an invalid key bindings error, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrLocked is returned when another process holds the config file lock.
+var ErrLocked = errors.New("config file is locked by another process")
+
+// lockRetryInterval is how often a waiting LockFile retries.
+const lockRetryInterval = 100 * time.Millisecond
+
+// Lock is an advisory lock on a config file, held on a separate path+".lock"
+// file because saving replaces the config file itself.
+type Lock struct {
+	unlock func() error
+}
+
+// LockPath returns the lock file used for the config file at path.
+func LockPath(path string) string {
+	return path + ".lock"
+}
+
+// LockFile acquires the lock for the config file at path. With a zero wait
+// it fails immediately with ErrLocked when the lock is held elsewhere;
+// otherwise it retries until wait has passed.
+func LockFile(path string, wait time.Duration) (*Lock, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), wait)
+	defer cancel()
+
+	for {
+		lock, err := tryLock(path)
+		if !errors.Is(err, ErrLocked) {
+			return lock, err
+		}
+		select {
+		case <-ctx.Done():
+			if wait > 0 {
+				return nil, fmt.Errorf("%w (waited %s)", ErrLocked, wait)
+			}
+			return nil, err
+		case <-time.After(lockRetryInterval):
+		}
+	}
+}
+
+// Unlock releases the lock. It is safe to call on a nil Lock.
+func (l *Lock) Unlock() error {
+	if l == nil || l.unlock == nil {
+		return nil
+	}
+	unlock := l.unlock
+	l.unlock = nil
+	return unlock()
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package config
+
+// tryLock is a no-op where flock(2) is not available; saves still use
+// unique temporary files and an atomic rename.
+func tryLock(path string) (*Lock, error) {
+	return &Lock{}, nil
+}
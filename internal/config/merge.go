@@ -0,0 +1,205 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// FileState identifies the content of the config file at one point in time.
+type FileState struct {
+	Exists  bool
+	ModTime time.Time
+	Size    int64
+	Sum     [sha256.Size]byte
+}
+
+// StatFile reads the current state of the file at path. A missing file is
+// not an error; it yields a state with Exists false.
+func StatFile(path string) (FileState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return FileState{}, nil
+		}
+		return FileState{}, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return FileState{}, err
+	}
+	return FileState{Exists: true, ModTime: info.ModTime(), Size: info.Size(), Sum: sha256.Sum256(data)}, nil
+}
+
+// Changed reports whether the file content differs between the two states.
+// The modification time alone is not enough, since touching a file or
+// rewriting the same bytes is not a change worth reporting.
+func (s FileState) Changed(other FileState) bool {
+	return s.Exists != other.Exists || s.Sum != other.Sum
+}
+
+// MergeConflict is a model that both sides changed in different ways. The
+// merge keeps Ours; Theirs is the version found on disk.
+type MergeConflict struct {
+	Name   string
+	Ours   *CustomModel // nil when we deleted it
+	Theirs *CustomModel // nil when they deleted it
+}
+
+func (c MergeConflict) String() string {
+	switch {
+	case c.Ours == nil:
+		return fmt.Sprintf("%q was deleted here but changed on disk", c.Name)
+	case c.Theirs == nil:
+		return fmt.Sprintf("%q was changed here but deleted on disk", c.Name)
+	}
+	return fmt.Sprintf("%q was changed both here and on disk", c.Name)
+}
+
+// MergeModels does a three-way merge of model lists: base is the list both
+// sides started from, ours the local edits and theirs the list now on disk.
+// Models are matched by display name (case-insensitive, numbered when
+// repeated). Changes made on one side only are applied; when both sides
+// changed the same model differently ours wins and a conflict is reported.
+// The merged list keeps our order, with models added on disk placed after
+// the model preceding them there.
+func MergeModels(base, ours, theirs []CustomModel) ([]CustomModel, []MergeConflict) {
+	baseByKey := keyModels(base)
+	theirsByKey := keyModels(theirs)
+	oursKeys := modelKeys(ours)
+	theirsKeys := modelKeys(theirs)
+
+	var merged []CustomModel
+	var mergedKeys []string
+	var conflicts []MergeConflict
+	inOurs := make(map[string]bool, len(ours))
+
+	for i, m := range ours {
+		key := oursKeys[i]
+		inOurs[key] = true
+		b, inBase := baseByKey[key]
+		t, inTheirs := theirsByKey[key]
+
+		switch {
+		case !inBase && !inTheirs, inTheirs && sameModel(m, t):
+			// Added here, or identical on both sides.
+		case inBase && !inTheirs:
+			if sameModel(m, b) {
+				continue // deleted on disk, untouched here
+			}
+			ours := m
+			conflicts = append(conflicts, MergeConflict{Name: m.DisplayName, Ours: &ours})
+		case inBase && sameModel(m, b):
+			m = t // changed on disk only
+		case inBase && sameModel(t, b):
+			// changed here only
+		default:
+			ours, theirs := m, t
+			conflicts = append(conflicts, MergeConflict{Name: m.DisplayName, Ours: &ours, Theirs: &theirs})
+		}
+		merged = append(merged, m)
+		mergedKeys = append(mergedKeys, key)
+	}
+
+	oursByKey := keyModels(ours)
+	for i, t := range theirs {
+		key := theirsKeys[i]
+		if inOurs[key] {
+			continue
+		}
+		if b, inBase := baseByKey[key]; inBase {
+			// We deleted it; keep it deleted unless they changed it.
+			if !sameModel(t, b) {
+				theirs := t
+				conflicts = append(conflicts, MergeConflict{Name: t.DisplayName, Theirs: &theirs})
+			}
+			continue
+		}
+		if _, ok := oursByKey[key]; ok {
+			continue
+		}
+
+		// Added on disk: insert after its predecessor there, if we have it.
+		pos := len(merged)
+		for j := i - 1; j >= 0; j-- {
+			if k := indexOf(mergedKeys, theirsKeys[j]); k >= 0 {
+				pos = k + 1
+				break
+			}
+		}
+		merged = append(merged[:pos], append([]CustomModel{t}, merged[pos:]...)...)
+		mergedKeys = append(mergedKeys[:pos], append([]string{key}, mergedKeys[pos:]...)...)
+	}
+
+	if merged == nil {
+		merged = []CustomModel{}
+	}
+	return merged, conflicts
+}
+
+// modelKeys returns the merge key of each model: its lowercased display
+// name, suffixed with the occurrence number for repeated names.
+func modelKeys(models []CustomModel) []string {
+	keys := make([]string, len(models))
+	seen := make(map[string]int)
+	for i, m := range models {
+		name := strings.ToLower(strings.TrimSpace(m.DisplayName))
+		seen[name]++
+		keys[i] = fmt.Sprintf("%s#%d", name, seen[name])
+	}
+	return keys
+}
+
+func keyModels(models []CustomModel) map[string]CustomModel {
+	byKey := make(map[string]CustomModel, len(models))
+	for i, key := range modelKeys(models) {
+		byKey[key] = models[i]
+	}
+	return byKey
+}
+
+func indexOf(keys []string, key string) int {
+	for i, k := range keys {
+		if k == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func sameModel(a, b CustomModel) bool {
+	return a.Equal(b)
+}
+
+// MergeExtra merges the top-level values other than custom_models. Keys we
+// added, changed or removed since base take our side; all others take theirs.
+func MergeExtra(base, ours, theirs map[string]json.RawMessage) map[string]json.RawMessage {
+	merged := make(map[string]json.RawMessage, len(theirs))
+	for k, v := range theirs {
+		merged[k] = v
+	}
+	keys := make(map[string]bool)
+	for k := range base {
+		keys[k] = true
+	}
+	for k := range ours {
+		keys[k] = true
+	}
+	for k := range keys {
+		b, inBase := base[k]
+		o, inOurs := ours[k]
+		if inBase == inOurs && bytes.Equal(b, o) {
+			continue
+		}
+		if inOurs {
+			merged[k] = o
+		} else {
+			delete(merged, k)
+		}
+	}
+	return merged
+}
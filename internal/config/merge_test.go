@@ -0,0 +1,131 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func names(models []CustomModel) []string {
+	out := make([]string, len(models))
+	for i, m := range models {
+		out[i] = m.DisplayName
+	}
+	return out
+}
+
+func equalNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestMergeModels(t *testing.T) {
+	a := CustomModel{DisplayName: "A", Model: "a"}
+	b := CustomModel{DisplayName: "B", Model: "b"}
+	c := CustomModel{DisplayName: "C", Model: "c"}
+	base := []CustomModel{a, b, c}
+
+	// Here: B edited, D added at the end. On disk: C edited, A deleted, E added after B.
+	bOurs := b
+	bOurs.Model = "b2"
+	cTheirs := c
+	cTheirs.Model = "c2"
+	d := CustomModel{DisplayName: "D"}
+	e := CustomModel{DisplayName: "E"}
+	ours := []CustomModel{a, bOurs, c, d}
+	theirs := []CustomModel{b, e, cTheirs}
+
+	merged, conflicts := MergeModels(base, ours, theirs)
+	if len(conflicts) != 0 {
+		t.Errorf("Expected no conflicts, got %v", conflicts)
+	}
+	if want := []string{"B", "E", "C", "D"}; !equalNames(names(merged), want) {
+		t.Fatalf("Expected %v, got %v", want, names(merged))
+	}
+	if merged[0].Model != "b2" || merged[2].Model != "c2" {
+		t.Errorf("Expected both edits to be kept, got %+v", merged)
+	}
+}
+
+func TestMergeModelsConflicts(t *testing.T) {
+	a := CustomModel{DisplayName: "A", Model: "a"}
+	b := CustomModel{DisplayName: "B", Model: "b"}
+	base := []CustomModel{a, b}
+
+	aOurs, aTheirs := a, a
+	aOurs.Model = "ours"
+	aTheirs.Model = "theirs"
+	bTheirs := b
+	bTheirs.Model = "theirs"
+
+	// A changed on both sides; B deleted here but changed on disk.
+	merged, conflicts := MergeModels(base, []CustomModel{aOurs}, []CustomModel{aTheirs, bTheirs})
+	if len(conflicts) != 2 {
+		t.Fatalf("Expected 2 conflicts, got %v", conflicts)
+	}
+	if len(merged) != 1 || merged[0].Model != "ours" {
+		t.Errorf("Expected our version to win, got %+v", merged)
+	}
+	if conflicts[1].Ours != nil || conflicts[1].Theirs == nil {
+		t.Errorf("Expected a delete/modify conflict for B, got %+v", conflicts[1])
+	}
+}
+
+func TestMergeExtra(t *testing.T) {
+	raw := func(s string) json.RawMessage { return json.RawMessage(s) }
+	base := map[string]json.RawMessage{"a": raw("1"), "b": raw("2"), "c": raw("3"), "d": raw("4")}
+	// Here: a changed, b removed, e added. On disk: c changed, d removed, f added.
+	ours := map[string]json.RawMessage{"a": raw("10"), "c": raw("3"), "d": raw("4"), "e": raw("5")}
+	theirs := map[string]json.RawMessage{"a": raw("1"), "b": raw("2"), "c": raw("30"), "f": raw("6")}
+
+	merged := MergeExtra(base, ours, theirs)
+	want := map[string]string{"a": "10", "c": "30", "e": "5", "f": "6"}
+	if len(merged) != len(want) {
+		t.Errorf("Expected %d keys, got %v", len(want), merged)
+	}
+	for k, v := range want {
+		if string(merged[k]) != v {
+			t.Errorf("Expected %s=%s, got %s", k, v, merged[k])
+		}
+	}
+}
+
+func TestFileStateChanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	missing, err := StatFile(path)
+	if err != nil || missing.Exists {
+		t.Fatalf("Expected a missing file state, got %+v, %v", missing, err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	first, _ := StatFile(path)
+	if !first.Changed(missing) {
+		t.Error("Expected creating the file to be a change")
+	}
+
+	if err := os.WriteFile(path, []byte(`{}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	same, _ := StatFile(path)
+	if same.Changed(first) {
+		t.Error("Expected rewriting the same content not to be a change")
+	}
+
+	if err := os.WriteFile(path, []byte(`{"custom_models": []}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	changed, _ := StatFile(path)
+	if !changed.Changed(first) {
+		t.Error("Expected new content to be a change")
+	}
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// SecretKind says how an API key value is stored.
+type SecretKind string
+
+const (
+	SecretPlain SecretKind = ""     // the value is the key itself
+	SecretEnv   SecretKind = "env"  // env:VAR reads an environment variable
+	SecretFile  SecretKind = "file" // file:/path reads a file
+	SecretCmd   SecretKind = "cmd"  // cmd:command runs a shell command
+)
+
+// Describe returns a short human description of the kind.
+func (k SecretKind) Describe() string {
+	switch k {
+	case SecretEnv:
+		return "environment variable"
+	case SecretFile:
+		return "file"
+	case SecretCmd:
+		return "command"
+	default:
+		return "plain text"
+	}
+}
+
+// ParseSecretRef splits an API key value into its kind and reference.
+// Anything without a recognized prefix is a plain key.
+func ParseSecretRef(value string) (SecretKind, string) {
+	for _, k := range []SecretKind{SecretEnv, SecretFile, SecretCmd} {
+		if ref, ok := strings.CutPrefix(value, string(k)+":"); ok && ref != "" {
+			return k, ref
+		}
+	}
+	return SecretPlain, value
+}
+
+// ResolveSecret turns an API key value into the key itself. References are
+// only resolved here, never when loading or saving the config.
+func ResolveSecret(ctx context.Context, value string) (string, error) {
+	kind, ref := ParseSecretRef(value)
+	switch kind {
+	case SecretEnv:
+		v, ok := os.LookupEnv(ref)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", ref)
+		}
+		return v, nil
+
+	case SecretFile:
+		if ref == "~" || strings.HasPrefix(ref, "~/") {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", err
+			}
+			ref = filepath.Join(home, ref[1:])
+		}
+		data, err := os.ReadFile(ref)
+		if err != nil {
+			return "", fmt.Errorf("reading key file: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+
+	case SecretCmd:
+		var stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, "sh", "-c", ref)
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return "", fmt.Errorf("key command failed: %v: %s", err, msg)
+			}
+			return "", fmt.Errorf("key command failed: %w", err)
+		}
+		// Like pass(1), only the first line holds the secret.
+		key, _, _ := strings.Cut(string(out), "\n")
+		return strings.TrimSpace(key), nil
+
+	default:
+		return value, nil
+	}
+}
+
+// MaskSecret hides most of a plain-text key for display; env:, file: and
+// cmd: references are returned as is since they hold no secret.
+func MaskSecret(value string) string {
+	if kind, _ := ParseSecretRef(value); value == "" || kind != SecretPlain {
+		return value
+	}
+	if len(value) <= 8 {
+		return strings.Repeat("*", len(value))
+	}
+	return value[:4] + strings.Repeat("*", len(value)-8) + value[len(value)-4:]
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseSecretRef(t *testing.T) {
+	cases := []struct {
+		value string
+		kind  SecretKind
+		ref   string
+	}{
+		{"sk-plain", SecretPlain, "sk-plain"},
+		{"env:OPENAI_API_KEY", SecretEnv, "OPENAI_API_KEY"},
+		{"file:/run/secret", SecretFile, "/run/secret"},
+		{"cmd:pass show openai", SecretCmd, "pass show openai"},
+		{"env:", SecretPlain, "env:"},
+	}
+	for _, c := range cases {
+		kind, ref := ParseSecretRef(c.value)
+		if kind != c.kind || ref != c.ref {
+			t.Errorf("ParseSecretRef(%q) = %q, %q; want %q, %q", c.value, kind, ref, c.kind, c.ref)
+		}
+	}
+}
+
+func TestResolveSecret(t *testing.T) {
+	ctx := context.Background()
+
+	t.Setenv("DROID_TEST_KEY", "from-env")
+	if v, err := ResolveSecret(ctx, "env:DROID_TEST_KEY"); err != nil || v != "from-env" {
+		t.Errorf("env: got %q, %v", v, err)
+	}
+	if _, err := ResolveSecret(ctx, "env:DROID_TEST_MISSING"); err == nil {
+		t.Error("Expected error for unset variable")
+	}
+
+	path := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(path, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := ResolveSecret(ctx, "file:"+path); err != nil || v != "from-file" {
+		t.Errorf("file: got %q, %v", v, err)
+	}
+
+	if v, err := ResolveSecret(ctx, "cmd:printf 'from-cmd\\nmetadata'"); err != nil || v != "from-cmd" {
+		t.Errorf("cmd: got %q, %v", v, err)
+	}
+	if _, err := ResolveSecret(ctx, "cmd:exit 3"); err == nil {
+		t.Error("Expected error for failing command")
+	}
+
+	if v, _ := ResolveSecret(ctx, "sk-plain"); v != "sk-plain" {
+		t.Errorf("plain: got %q", v)
+	}
+}
+
+func TestSaveKeepsReferencesAndRestrictsMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ConfigFileName)
+	cfg := &ConfigData{CustomModels: []CustomModel{{DisplayName: "A", APIKey: "env:HOME"}}}
+	if err := Save(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.CustomModels[0].APIKey != "env:HOME" {
+		t.Errorf("Reference was expanded on save: %q", loaded.CustomModels[0].APIKey)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("Expected mode 0600, got %v", info.Mode().Perm())
+	}
+}
+
+func TestMaskSecret(t *testing.T) {
+	tests := map[string]string{
+		"":                   "",
+		"short":              "*****",
+		"sk-1234567890abcd":  "sk-1*********abcd",
+		"env:OPENAI_API_KEY": "env:OPENAI_API_KEY",
+	}
+	for in, want := range tests {
+		if got := MaskSecret(in); got != want {
+			t.Errorf("MaskSecret(%q): expected %q, got %q", in, want, got)
+		}
+	}
+}
@@ -1,7 +1,11 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
+	"maps"
+	"sort"
+	"strings"
 )
 
 type CustomModel struct {
@@ -11,11 +15,148 @@ type CustomModel struct {
 	APIKey      string `json:"api_key"`
 	Provider    string `json:"provider"`
 	MaxTokens   int    `json:"max_tokens"`
+
+	// extra holds the fields this version does not know about.
+	extra map[string]json.RawMessage
+}
+
+// customModelFields has the fields of CustomModel without its methods, so
+// the known fields can be encoded and decoded the default way.
+type customModelFields CustomModel
+
+// knownModelFields are the JSON keys of the exported CustomModel fields.
+var knownModelFields = []Field{
+	FieldDisplayName,
+	FieldModel,
+	FieldBaseURL,
+	FieldAPIKey,
+	FieldProvider,
+	FieldMaxTokens,
+}
+
+// ExtraKeys returns the sorted keys of the fields m does not know about,
+// which are preserved as is when the file is saved.
+func (m CustomModel) ExtraKeys() []string {
+	keys := make([]string, 0, len(m.extra))
+	for k := range m.extra {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Extra returns a copy of the fields m does not know about.
+func (m CustomModel) Extra() map[string]json.RawMessage {
+	return maps.Clone(m.extra)
+}
+
+// SetExtra replaces the fields m does not know about with a copy of extra.
+// Values must be valid JSON.
+func (m *CustomModel) SetExtra(extra map[string]json.RawMessage) {
+	if len(extra) == 0 {
+		m.extra = nil
+		return
+	}
+	m.extra = maps.Clone(extra)
+}
+
+// Equal reports whether m and o have the same fields, unknown ones included.
+func (m CustomModel) Equal(o CustomModel) bool {
+	return m.DisplayName == o.DisplayName &&
+		m.Model == o.Model &&
+		m.BaseURL == o.BaseURL &&
+		m.APIKey == o.APIKey &&
+		m.Provider == o.Provider &&
+		m.MaxTokens == o.MaxTokens &&
+		maps.EqualFunc(m.extra, o.extra, func(x, y json.RawMessage) bool {
+			return bytes.Equal(x, y)
+		})
+}
+
+func (m *CustomModel) UnmarshalJSON(data []byte) error {
+	var fields customModelFields
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	// encoding/json matches keys case-insensitively, so drop every spelling
+	// of a known field rather than keeping it twice.
+	for k := range raw {
+		for _, f := range knownModelFields {
+			if strings.EqualFold(k, string(f)) {
+				delete(raw, k)
+				break
+			}
+		}
+	}
+
+	*m = CustomModel(fields)
+	if len(raw) > 0 {
+		m.extra = raw
+	}
+	return nil
+}
+
+// MarshalJSON writes the known fields in their usual order, followed by the
+// unknown ones sorted by key.
+func (m CustomModel) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(customModelFields(m)); err != nil {
+		return nil, err
+	}
+	data := bytes.TrimSuffix(b.Bytes(), []byte("\n"))
+	if len(m.extra) == 0 {
+		return data, nil
+	}
+
+	b.Truncate(len(data) - 1)
+	for _, k := range m.ExtraKeys() {
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		b.WriteByte(',')
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(m.extra[k])
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
 }
 
 type ConfigData struct {
 	CustomModels []CustomModel
 	extra        map[string]json.RawMessage
+	source       *source // the document this config was parsed from, if any
+}
+
+// ExtraKeys returns the sorted top-level keys other than custom_models,
+// which are preserved as is when the file is saved.
+func (c *ConfigData) ExtraKeys() []string {
+	keys := make([]string, 0, len(c.extra))
+	for k := range c.extra {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Extra returns a copy of the top-level values other than custom_models.
+func (c *ConfigData) Extra() map[string]json.RawMessage {
+	return maps.Clone(c.extra)
+}
+
+// SetExtra replaces the top-level values other than custom_models with a
+// copy of extra. Values must be valid JSON.
+func (c *ConfigData) SetExtra(extra map[string]json.RawMessage) {
+	c.extra = maps.Clone(extra)
+	delete(c.extra, customModelsKey)
 }
 
 func (c *ConfigData) UnmarshalJSON(data []byte) error {
@@ -24,30 +165,37 @@ func (c *ConfigData) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	if cm, ok := raw["custom_models"]; ok {
+	if cm, ok := raw[customModelsKey]; ok {
 		if err := json.Unmarshal(cm, &c.CustomModels); err != nil {
 			return err
 		}
-		delete(raw, "custom_models")
+		delete(raw, customModelsKey)
 	}
 
 	c.extra = raw
-	return nil
+	return c.setSource(data)
 }
 
-func (c ConfigData) MarshalJSON() ([]byte, error) {
-	result := make(map[string]json.RawMessage)
-	for k, v := range c.extra {
-		result[k] = v
-	}
-
-	cm, err := json.Marshal(c.CustomModels)
+// setSource remembers data as the document c was parsed from, so that
+// MarshalJSON changes as little of it as possible.
+func (c *ConfigData) setSource(data []byte) error {
+	src, err := indexSource(append([]byte(nil), data...))
 	if err != nil {
-		return nil, err
+		return err
 	}
-	result["custom_models"] = cm
+	if cm, ok := src.original[customModelsKey]; ok {
+		if err := json.Unmarshal(cm, &src.models); err != nil {
+			return err
+		}
+	}
+	c.source = src
+	return nil
+}
 
-	return json.Marshal(result)
+// MarshalJSON keeps the key order and formatting of the document the config
+// was parsed from, rewriting only the values that changed.
+func (c ConfigData) MarshalJSON() ([]byte, error) {
+	return c.encode()
 }
 
 var Providers = []string{
@@ -1,10 +1,16 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
 func TestConfigDataMarshalUnmarshal(t *testing.T) {
 	input := `{
 		"custom_models": [
@@ -81,7 +87,7 @@ func TestEmptyConfig(t *testing.T) {
 
 func TestProviders(t *testing.T) {
 	expected := []string{"anthropic", "openai", "generic-chat-completion-api"}
-	
+
 	if len(Providers) != len(expected) {
 		t.Fatalf("Expected %d providers, got %d", len(expected), len(Providers))
 	}
@@ -92,3 +98,159 @@ func TestProviders(t *testing.T) {
 		}
 	}
 }
+
+// TestRoundTripGolden loads each testdata/roundtrip/<name>.json, applies an
+// edit and compares the saved bytes with <name>.golden.json. Run with
+// -update to rewrite the golden files after an intended change.
+func TestRoundTripGolden(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(cfg *ConfigData)
+	}{
+		{"unchanged", func(cfg *ConfigData) {}},
+		{"edit-model", func(cfg *ConfigData) {
+			cfg.CustomModels[1].MaxTokens = 16384
+		}},
+		{"compact", func(cfg *ConfigData) {
+			cfg.CustomModels = append(cfg.CustomModels, CustomModel{
+				DisplayName: "Groq",
+				Model:       "llama-3.3-70b",
+				BaseURL:     "https://api.groq.com/openai/v1?a=1&b=2",
+				Provider:    "generic-chat-completion-api",
+			})
+		}},
+		{"model-extras", func(cfg *ConfigData) {
+			cfg.CustomModels[0].MaxTokens = 8192
+		}},
+		{"settings", func(cfg *ConfigData) {
+			extra := cfg.Extra()
+			extra["telemetry"] = json.RawMessage(`{"enabled":true,"level":1}`)
+			extra["editor"] = json.RawMessage(`{"tabs":true}`)
+			delete(extra, "obsolete")
+			cfg.SetExtra(extra)
+		}},
+		{"no-models", func(cfg *ConfigData) {
+			cfg.CustomModels = append(cfg.CustomModels, CustomModel{DisplayName: "New", Provider: "openai"})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := filepath.Join("testdata", "roundtrip", tt.name+".json")
+			golden := filepath.Join("testdata", "roundtrip", tt.name+".golden.json")
+
+			cfg, err := Load(input)
+			if err != nil {
+				t.Fatalf("Failed to load: %v", err)
+			}
+			tt.edit(cfg)
+
+			path := filepath.Join(t.TempDir(), ConfigFileName)
+			data, _ := os.ReadFile(input)
+			if err := os.WriteFile(path, data, 0600); err != nil {
+				t.Fatal(err)
+			}
+			if err := SaveWith(path, cfg, SaveOptions{Backups: -1}); err != nil {
+				t.Fatalf("Failed to save: %v", err)
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if *update {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("Missing golden file (run with -update): %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("Output differs from %s:\n%s", golden, got)
+			}
+
+			if tt.name == "unchanged" && !bytes.Equal(got, data) {
+				t.Errorf("Saving an unchanged config modified the file:\n%s", got)
+			}
+		})
+	}
+}
+
+func TestMarshalNewConfig(t *testing.T) {
+	cfg := &ConfigData{CustomModels: []CustomModel{{DisplayName: "A", Provider: "openai"}}}
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	want := `{"custom_models":[{"model_display_name":"A","model":"","base_url":"","api_key":"","provider":"openai","max_tokens":0}]}`
+	if string(data) != want {
+		t.Errorf("Expected %s, got %s", want, data)
+	}
+}
+
+func TestCustomModelKeepsUnknownFields(t *testing.T) {
+	input := `{"model_display_name":"A","provider":"openai","temperature":0.5,"headers":{"X-A":"1"}}`
+
+	var m CustomModel
+	if err := json.Unmarshal([]byte(input), &m); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+	if keys := m.ExtraKeys(); len(keys) != 2 || keys[0] != "headers" || keys[1] != "temperature" {
+		t.Errorf("Expected extra keys [headers temperature], got %v", keys)
+	}
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	want := `{"model_display_name":"A","model":"","base_url":"","api_key":"","provider":"openai","max_tokens":0,"headers":{"X-A":"1"},"temperature":0.5}`
+	if string(data) != want {
+		t.Errorf("Expected %s, got %s", want, data)
+	}
+
+	var back CustomModel
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+	if !back.Equal(m) {
+		t.Errorf("Expected %+v after round trip, got %+v", m, back)
+	}
+}
+
+func TestCustomModelExtraIsCopied(t *testing.T) {
+	var m CustomModel
+	if err := json.Unmarshal([]byte(`{"model_display_name":"A","temperature":0.5}`), &m); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+
+	clone := m
+	extra := clone.Extra()
+	extra["temperature"] = json.RawMessage("1")
+	extra["top_p"] = json.RawMessage("0.9")
+	clone.SetExtra(extra)
+
+	if got := string(m.Extra()["temperature"]); got != "0.5" {
+		t.Errorf("Expected original temperature 0.5, got %s", got)
+	}
+	if len(m.ExtraKeys()) != 1 {
+		t.Errorf("Expected original to keep 1 extra key, got %v", m.ExtraKeys())
+	}
+	if m.Equal(clone) {
+		t.Error("Expected models with different extra fields to differ")
+	}
+}
+
+func TestCustomModelKnownFieldsAnyCase(t *testing.T) {
+	var m CustomModel
+	if err := json.Unmarshal([]byte(`{"Model_Display_Name":"A"}`), &m); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+	if m.DisplayName != "A" {
+		t.Errorf("Expected display name A, got %q", m.DisplayName)
+	}
+	if keys := m.ExtraKeys(); len(keys) != 0 {
+		t.Errorf("Expected no extra keys, got %v", keys)
+	}
+}
@@ -0,0 +1,258 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// Field names a CustomModel field by its JSON key.
+type Field string
+
+const (
+	FieldDisplayName Field = "model_display_name"
+	FieldModel       Field = "model"
+	FieldBaseURL     Field = "base_url"
+	FieldAPIKey      Field = "api_key"
+	FieldProvider    Field = "provider"
+	FieldMaxTokens   Field = "max_tokens"
+)
+
+// MaxTokensLimit is the largest max_tokens value accepted as plausible.
+const MaxTokensLimit = 1_000_000
+
+// minSensibleMaxTokens is the value below which max_tokens is likely a typo.
+const minSensibleMaxTokens = 256
+
+// Severity tells whether an issue blocks saving or is only a warning.
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	if s == SeverityWarning {
+		return "warning"
+	}
+	return "error"
+}
+
+// Issue is one problem found with a field of a model.
+type Issue struct {
+	Field    Field
+	Severity Severity
+	Message  string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s", i.Field, i.Message)
+}
+
+// Issues is the list of problems found with one model.
+type Issues []Issue
+
+// HasErrors reports whether any issue has SeverityError.
+func (is Issues) HasErrors() bool {
+	for _, i := range is {
+		if i.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// ForField returns the issues of field f in the order they were found.
+func (is Issues) ForField(f Field) Issues {
+	var out Issues
+	for _, i := range is {
+		if i.Field == f {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+func (is *Issues) add(f Field, s Severity, format string, args ...any) {
+	*is = append(*is, Issue{Field: f, Severity: s, Message: fmt.Sprintf(format, args...)})
+}
+
+// keyPrefixes maps well-known plain key prefixes to the vendor issuing them.
+// Longer prefixes come first so that "sk-ant-" wins over "sk-".
+var keyPrefixes = []struct {
+	prefix string
+	vendor string
+}{
+	{"sk-ant-", "Anthropic"},
+	{"sk-or-", "OpenRouter"},
+	{"sk-proj-", "OpenAI"},
+	{"gsk_", "Groq"},
+	{"xai-", "xAI"},
+	{"AIza", "Google"},
+}
+
+// KnownProvider reports whether p is one of Providers.
+func KnownProvider(p string) bool {
+	for _, known := range Providers {
+		if p == known {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateModel checks the fields of a single model and returns every
+// problem found, not just the first one.
+func ValidateModel(m CustomModel) Issues {
+	var issues Issues
+
+	if strings.TrimSpace(m.DisplayName) == "" {
+		issues.add(FieldDisplayName, SeverityError, "required")
+	}
+
+	if strings.TrimSpace(m.Model) == "" {
+		issues.add(FieldModel, SeverityError, "required for provider %s", providerName(m.Provider))
+	} else if strings.TrimSpace(m.Model) != m.Model {
+		issues.add(FieldModel, SeverityWarning, "has leading or trailing spaces")
+	}
+
+	validateBaseURL(&issues, m)
+	validateAPIKey(&issues, m)
+
+	if !KnownProvider(m.Provider) {
+		issues.add(FieldProvider, SeverityError, "unknown provider %q (expected one of: %s)", m.Provider, strings.Join(Providers, ", "))
+	}
+
+	switch {
+	case m.MaxTokens < 0:
+		issues.add(FieldMaxTokens, SeverityError, "must not be negative")
+	case m.MaxTokens > MaxTokensLimit:
+		issues.add(FieldMaxTokens, SeverityError, "must be at most %d", MaxTokensLimit)
+	case m.MaxTokens > 0 && m.MaxTokens < minSensibleMaxTokens:
+		issues.add(FieldMaxTokens, SeverityWarning, "%d is unusually low", m.MaxTokens)
+	}
+
+	return issues
+}
+
+// ValidateModels checks every model and the constraints between them, such
+// as unique display names. The result has one entry per model.
+func ValidateModels(models []CustomModel) []Issues {
+	result := make([]Issues, len(models))
+	byName := make(map[string][]int)
+	for i, m := range models {
+		result[i] = ValidateModel(m)
+		if name := strings.ToLower(strings.TrimSpace(m.DisplayName)); name != "" {
+			byName[name] = append(byName[name], i)
+		}
+	}
+
+	for i, m := range models {
+		name := strings.ToLower(strings.TrimSpace(m.DisplayName))
+		var others []string
+		for _, j := range byName[name] {
+			if j != i {
+				others = append(others, fmt.Sprintf("#%d", j+1))
+			}
+		}
+		if len(others) > 0 {
+			result[i].add(FieldDisplayName, SeverityError, "duplicate display name (also %s)", strings.Join(others, ", "))
+		}
+	}
+	return result
+}
+
+func validateBaseURL(issues *Issues, m CustomModel) {
+	raw := strings.TrimSpace(m.BaseURL)
+	if raw == "" {
+		issues.add(FieldBaseURL, SeverityError, "required for provider %s", providerName(m.Provider))
+		return
+	}
+	if raw != m.BaseURL {
+		issues.add(FieldBaseURL, SeverityWarning, "has leading or trailing spaces")
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		issues.add(FieldBaseURL, SeverityError, "not a valid URL")
+		return
+	}
+	switch u.Scheme {
+	case "https":
+	case "http":
+		if !isLocalHost(u.Hostname()) {
+			issues.add(FieldBaseURL, SeverityWarning, "uses plain http; the API key is sent unencrypted")
+		}
+	case "":
+		issues.add(FieldBaseURL, SeverityError, "missing scheme (expected https:// or http://)")
+		return
+	default:
+		issues.add(FieldBaseURL, SeverityError, "unsupported scheme %q (expected http or https)", u.Scheme)
+		return
+	}
+	if u.Host == "" {
+		issues.add(FieldBaseURL, SeverityError, "missing host")
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		issues.add(FieldBaseURL, SeverityWarning, "query strings and fragments are dropped when requests are built")
+	}
+}
+
+func validateAPIKey(issues *Issues, m CustomModel) {
+	if m.APIKey == "" {
+		issues.add(FieldAPIKey, SeverityWarning, "no API key set")
+		return
+	}
+
+	kind, ref := ParseSecretRef(m.APIKey)
+	switch kind {
+	case SecretEnv:
+		if strings.ContainsAny(ref, " \t=") {
+			issues.add(FieldAPIKey, SeverityError, "%q is not a valid environment variable name", ref)
+		}
+		return
+	case SecretFile, SecretCmd:
+		return
+	}
+	for _, k := range []SecretKind{SecretEnv, SecretFile, SecretCmd} {
+		if m.APIKey == string(k)+":" {
+			issues.add(FieldAPIKey, SeverityError, "empty %s reference", k.Describe())
+			return
+		}
+	}
+
+	if strings.ContainsAny(m.APIKey, " \t\r\n") {
+		issues.add(FieldAPIKey, SeverityError, "contains whitespace")
+	}
+
+	vendor := ""
+	for _, p := range keyPrefixes {
+		if strings.HasPrefix(m.APIKey, p.prefix) {
+			vendor = p.vendor
+			break
+		}
+	}
+	switch {
+	case vendor == "Anthropic" && m.Provider != "anthropic":
+		issues.add(FieldAPIKey, SeverityWarning, "looks like an Anthropic key but the provider is %s", providerName(m.Provider))
+	case vendor != "" && vendor != "Anthropic" && m.Provider == "anthropic":
+		issues.add(FieldAPIKey, SeverityWarning, "looks like a %s key but the provider is anthropic", vendor)
+	}
+}
+
+func providerName(p string) string {
+	if p == "" {
+		return "(none)"
+	}
+	return p
+}
+
+func isLocalHost(host string) bool {
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") || strings.HasSuffix(host, ".local") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && (ip.IsLoopback() || ip.IsPrivate())
+}
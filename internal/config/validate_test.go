@@ -0,0 +1,109 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func validModel() CustomModel {
+	return CustomModel{
+		DisplayName: "GPT-4o",
+		Model:       "gpt-4o",
+		BaseURL:     "https://api.openai.com/v1",
+		APIKey:      "env:OPENAI_API_KEY",
+		Provider:    "openai",
+		MaxTokens:   4096,
+	}
+}
+
+func TestValidateModelAcceptsValidModel(t *testing.T) {
+	if issues := ValidateModel(validModel()); len(issues) != 0 {
+		t.Errorf("Expected no issues, got %v", issues)
+	}
+}
+
+func TestValidateModelReportsEveryField(t *testing.T) {
+	m := CustomModel{
+		BaseURL:   "ftp://example.com",
+		APIKey:    "env:",
+		Provider:  "bogus",
+		MaxTokens: -1,
+	}
+	issues := ValidateModel(m)
+	if !issues.HasErrors() {
+		t.Fatal("Expected errors")
+	}
+	for _, f := range []Field{FieldDisplayName, FieldModel, FieldBaseURL, FieldAPIKey, FieldProvider, FieldMaxTokens} {
+		if len(issues.ForField(f)) == 0 {
+			t.Errorf("Expected an issue for %s, got %v", f, issues)
+		}
+	}
+}
+
+func TestValidateModelBaseURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		severity Severity
+		message  string
+	}{
+		{"api.openai.com/v1", SeverityError, "missing scheme"},
+		{"https://", SeverityError, "missing host"},
+		{"http://api.example.com/v1", SeverityWarning, "plain http"},
+		{"http://localhost:11434/v1", -1, ""},
+		{"http://192.168.1.10:8000/v1", -1, ""},
+	}
+	for _, tt := range tests {
+		m := validModel()
+		m.BaseURL = tt.url
+		issues := ValidateModel(m).ForField(FieldBaseURL)
+		if tt.severity < 0 {
+			if len(issues) != 0 {
+				t.Errorf("%s: expected no issues, got %v", tt.url, issues)
+			}
+			continue
+		}
+		if len(issues) != 1 || issues[0].Severity != tt.severity || !strings.Contains(issues[0].Message, tt.message) {
+			t.Errorf("%s: expected %s containing %q, got %v", tt.url, tt.severity, tt.message, issues)
+		}
+	}
+}
+
+func TestValidateModelSuspiciousKeyPrefix(t *testing.T) {
+	m := validModel()
+	m.APIKey = "sk-ant-api03-abcdef"
+	issues := ValidateModel(m).ForField(FieldAPIKey)
+	if len(issues) != 1 || issues[0].Severity != SeverityWarning {
+		t.Fatalf("Expected one warning for an Anthropic key with openai, got %v", issues)
+	}
+
+	m.Provider = "anthropic"
+	m.BaseURL = "https://api.anthropic.com"
+	if issues := ValidateModel(m); len(issues) != 0 {
+		t.Errorf("Expected no issues for an Anthropic key with anthropic, got %v", issues)
+	}
+
+	m.APIKey = "gsk_abcdef"
+	if issues := ValidateModel(m).ForField(FieldAPIKey); len(issues) != 1 {
+		t.Errorf("Expected a warning for a Groq key with anthropic, got %v", issues)
+	}
+}
+
+func TestValidateModelsDuplicateNames(t *testing.T) {
+	a, b, c := validModel(), validModel(), validModel()
+	b.DisplayName = " gpt-4o "
+	c.DisplayName = "Other"
+
+	result := ValidateModels([]CustomModel{a, b, c})
+	for i, want := range []string{"#2", "#1", ""} {
+		issues := result[i].ForField(FieldDisplayName)
+		if want == "" {
+			if len(issues) != 0 {
+				t.Errorf("Model %d: expected no name issues, got %v", i+1, issues)
+			}
+			continue
+		}
+		if len(issues) != 1 || !strings.Contains(issues[0].Message, want) {
+			t.Errorf("Model %d: expected duplicate of %s, got %v", i+1, want, issues)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+// Package endpoint talks to the API behind a CustomModel, e.g. to check that
+// its base URL, key and model ID actually work before the droid uses them.
+package endpoint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/diogo/droid-config/internal/config"
+)
+
+// anthropicVersion is the API version sent to Anthropic-compatible endpoints.
+const anthropicVersion = "2023-06-01"
+
+// maxBodyBytes caps how much of an error response is read.
+const maxBodyBytes = 4096
+
+// Result describes the outcome of a connection test.
+type Result struct {
+	URL     string
+	Status  int
+	Latency time.Duration
+	Body    string // error message or body excerpt for non-2xx responses
+	Err     error  // transport-level failure; Status is 0 when set
+}
+
+// OK reports whether the endpoint answered with a 2xx status.
+func (r Result) OK() bool {
+	return r.Err == nil && r.Status >= 200 && r.Status < 300
+}
+
+// Summary renders the result as a single status-bar line.
+func (r Result) Summary() string {
+	latency := r.Latency.Round(time.Millisecond)
+	switch {
+	case r.Err != nil:
+		return fmt.Sprintf("Connection failed after %s: %v", latency, r.Err)
+	case r.OK():
+		return fmt.Sprintf("Connection OK: HTTP %d in %s", r.Status, latency)
+	case r.Body != "":
+		return fmt.Sprintf("HTTP %d in %s: %s", r.Status, latency, r.Body)
+	default:
+		return fmt.Sprintf("HTTP %d in %s", r.Status, latency)
+	}
+}
+
+// Test sends the smallest request the provider accepts (a one-token
+// completion) and reports how the endpoint responded.
+func Test(ctx context.Context, client *http.Client, m config.CustomModel) Result {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if m.BaseURL == "" {
+		return Result{Err: fmt.Errorf("base URL is empty")}
+	}
+	m, err := resolveKey(ctx, m)
+	if err != nil {
+		return Result{Err: err}
+	}
+
+	req, err := newTestRequest(ctx, m)
+	if err != nil {
+		return Result{Err: err}
+	}
+	res := Result{URL: req.URL.String()}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	res.Latency = time.Since(start)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	defer resp.Body.Close()
+
+	res.Status = resp.StatusCode
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
+	if !res.OK() {
+		res.Body = errorMessage(body)
+	}
+	return res
+}
+
+// ListModels fetches the model IDs the endpoint offers from its /models
+// listing, which OpenAI-compatible and Anthropic APIs share. IDs are sorted.
+func ListModels(ctx context.Context, client *http.Client, m config.CustomModel) ([]string, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if m.BaseURL == "" {
+		return nil, fmt.Errorf("base URL is empty")
+	}
+	m, err := resolveKey(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	after := ""
+	for {
+		u := apiURL(m, "/models")
+		if m.Provider == "anthropic" {
+			q := url.Values{"limit": {"1000"}}
+			if after != "" {
+				q.Set("after_id", after)
+			}
+			u += "?" + q.Encode()
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		setAuth(req, m)
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			if len(body) > maxBodyBytes {
+				body = body[:maxBodyBytes]
+			}
+			return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, errorMessage(body))
+		}
+
+		var page struct {
+			Data []struct {
+				ID string `json:"id"`
+			} `json:"data"`
+			HasMore bool   `json:"has_more"`
+			LastID  string `json:"last_id"`
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("unexpected model listing: %w", err)
+		}
+		for _, d := range page.Data {
+			if d.ID != "" {
+				ids = append(ids, d.ID)
+			}
+		}
+		if !page.HasMore || page.LastID == "" || page.LastID == after {
+			break
+		}
+		after = page.LastID
+	}
+
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// resolveKey replaces an env:, file: or cmd: key reference with the key itself.
+func resolveKey(ctx context.Context, m config.CustomModel) (config.CustomModel, error) {
+	key, err := config.ResolveSecret(ctx, m.APIKey)
+	if err != nil {
+		return m, fmt.Errorf("resolving API key: %w", err)
+	}
+	m.APIKey = key
+	return m, nil
+}
+
+func newTestRequest(ctx context.Context, m config.CustomModel) (*http.Request, error) {
+	payload := map[string]any{
+		"model":      m.Model,
+		"max_tokens": 1,
+		"messages": []map[string]string{
+			{"role": "user", "content": "ping"},
+		},
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	path := "/chat/completions"
+	if m.Provider == "anthropic" {
+		path = "/messages"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL(m, path), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	setAuth(req, m)
+	return req, nil
+}
+
+// apiURL joins the base URL and an API path. Anthropic base URLs are usually
+// given without the /v1 suffix, OpenAI-style ones with it.
+func apiURL(m config.CustomModel, path string) string {
+	base := strings.TrimRight(m.BaseURL, "/")
+	if m.Provider == "anthropic" && !strings.HasSuffix(base, "/v1") {
+		base += "/v1"
+	}
+	return base + path
+}
+
+func setAuth(req *http.Request, m config.CustomModel) {
+	if m.Provider == "anthropic" {
+		req.Header.Set("anthropic-version", anthropicVersion)
+		if m.APIKey != "" {
+			req.Header.Set("x-api-key", m.APIKey)
+		}
+		return
+	}
+	if m.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+m.APIKey)
+	}
+}
+
+// errorMessage extracts error.message from a JSON error body, falling back to
+// the first line of the raw body.
+func errorMessage(body []byte) string {
+	var parsed struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(body, &parsed) == nil && len(parsed.Error) > 0 {
+		var detail struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(parsed.Error, &detail) == nil && detail.Message != "" {
+			return detail.Message
+		}
+		var text string
+		if json.Unmarshal(parsed.Error, &text) == nil && text != "" {
+			return text
+		}
+	}
+
+	text := strings.TrimSpace(string(body))
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = text[:i]
+	}
+	return text
+}
@@ -0,0 +1,107 @@
+package endpoint
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/diogo/droid-config/internal/config"
+)
+
+func TestAnthropicRequestShape(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" {
+			t.Errorf("Expected /v1/messages, got %s", r.URL.Path)
+		}
+		if r.Header.Get("x-api-key") != "sk-ant-test" || r.Header.Get("anthropic-version") == "" {
+			t.Errorf("Missing Anthropic headers: %v", r.Header)
+		}
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body["model"] != "claude-test" {
+			t.Errorf("Unexpected body %v (%v)", body, err)
+		}
+		w.Write([]byte(`{"id": "msg"}`))
+	}))
+	defer srv.Close()
+
+	t.Setenv("DROID_TEST_ANTHROPIC_KEY", "sk-ant-test")
+	res := Test(context.Background(), srv.Client(), config.CustomModel{
+		Model:    "claude-test",
+		BaseURL:  srv.URL,
+		APIKey:   "env:DROID_TEST_ANTHROPIC_KEY",
+		Provider: "anthropic",
+	})
+	if !res.OK() {
+		t.Fatalf("Expected success, got %s", res.Summary())
+	}
+}
+
+func TestOpenAIErrorBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("Expected /v1/chat/completions, got %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer sk-bad" {
+			t.Errorf("Unexpected Authorization header %q", r.Header.Get("Authorization"))
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error": {"message": "Incorrect API key provided"}}`))
+	}))
+	defer srv.Close()
+
+	res := Test(context.Background(), srv.Client(), config.CustomModel{
+		Model:    "gpt-test",
+		BaseURL:  srv.URL + "/v1/",
+		APIKey:   "sk-bad",
+		Provider: "openai",
+	})
+	if res.OK() || res.Status != http.StatusUnauthorized {
+		t.Fatalf("Expected 401, got %s", res.Summary())
+	}
+	if res.Body != "Incorrect API key provided" {
+		t.Errorf("Expected error message from body, got %q", res.Body)
+	}
+	if !strings.Contains(res.Summary(), "HTTP 401") {
+		t.Errorf("Unexpected summary %q", res.Summary())
+	}
+}
+
+func TestTransportError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	res := Test(context.Background(), nil, config.CustomModel{BaseURL: url, Provider: "generic-chat-completion-api"})
+	if res.Err == nil || res.OK() {
+		t.Fatalf("Expected transport error, got %s", res.Summary())
+	}
+}
+
+func TestListModels(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/models" {
+			t.Errorf("Expected /v1/models, got %s", r.URL.Path)
+		}
+		switch after := r.URL.Query().Get("after_id"); after {
+		case "":
+			w.Write([]byte(`{"data": [{"id": "claude-b"}], "has_more": true, "last_id": "b&limit=1 #2"}`))
+			return
+		case "b&limit=1 #2":
+		default:
+			t.Errorf("Expected the cursor to be passed back unchanged, got %q", after)
+		}
+		w.Write([]byte(`{"data": [{"id": "claude-a"}], "has_more": false}`))
+	}))
+	defer srv.Close()
+
+	ids, err := ListModels(context.Background(), srv.Client(), config.CustomModel{BaseURL: srv.URL, Provider: "anthropic"})
+	if err != nil {
+		t.Fatalf("ListModels failed: %v", err)
+	}
+	if strings.Join(ids, ",") != "claude-a,claude-b" {
+		t.Errorf("Expected both pages sorted, got %v", ids)
+	}
+}
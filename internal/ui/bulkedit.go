@@ -0,0 +1,334 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/diogo/droid-config/internal/config"
+)
+
+// Fields the bulk editor can change, in focus order.
+const (
+	bulkBaseURL = iota
+	bulkAPIKey
+	bulkProvider
+	bulkMaxTokens
+	bulkFieldCount
+)
+
+var bulkFieldLabels = [bulkFieldCount]string{"Base URL", "API Key", "Provider", "Max Tokens"}
+
+// bulkChange is the effect of a bulk edit on one model.
+type bulkChange struct {
+	index  int
+	before config.CustomModel
+	after  config.CustomModel
+	issues config.Issues // problems the edit introduces
+}
+
+// bulkEditView applies the filled-in fields to every selected model. Empty
+// fields are left alone; the changes are previewed before they are saved.
+type bulkEditView struct {
+	indices  []int
+	inputs   [bulkFieldCount]textinput.Model // the provider slot is unused
+	provider int                             // 0 keeps each model's provider, otherwise config.Providers[provider-1]
+	focus    int
+	changes  []bulkChange // set while previewing
+	offset   int
+}
+
+func (v *bulkEditView) previewing() bool {
+	return v.changes != nil
+}
+
+func (v *bulkEditView) setFocus(field int) {
+	v.focus = (field + bulkFieldCount) % bulkFieldCount
+	for i := range v.inputs {
+		if i == v.focus {
+			v.inputs[i].Focus()
+		} else {
+			v.inputs[i].Blur()
+		}
+	}
+}
+
+// openBulkEdit starts a bulk edit of the selected models.
+func (m Model) openBulkEdit() (tea.Model, tea.Cmd) {
+	indices := m.list.GetSelectedIndices()
+	if len(indices) == 0 {
+		m.status.SetWarning("Select models with space to bulk edit them")
+		return m, statusClearCmd()
+	}
+
+	v := &bulkEditView{indices: indices}
+	placeholders := [bulkFieldCount]string{
+		bulkBaseURL:   "unchanged",
+		bulkAPIKey:    "unchanged (key or env:, file:, cmd: reference)",
+		bulkMaxTokens: "unchanged",
+	}
+	for i := range v.inputs {
+		t := textinput.New()
+		t.CharLimit = 1024
+		t.Placeholder = placeholders[i]
+		v.inputs[i] = t
+	}
+	v.inputs[bulkMaxTokens].CharLimit = 10
+	v.setFocus(bulkBaseURL)
+	m.bulkEdit = v
+	return m, textinput.Blink
+}
+
+func (m Model) handleBulkEditKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	v := m.bulkEdit
+	if key.Matches(msg, Keys.Quit) {
+		return m.quit(msg)
+	}
+
+	if v.previewing() {
+		switch {
+		case key.Matches(msg, Keys.Escape):
+			v.changes = nil
+			v.setFocus(v.focus)
+		case key.Matches(msg, Keys.Up):
+			v.offset = max(0, v.offset-1)
+		case key.Matches(msg, Keys.Down):
+			v.offset = min(v.offset+1, max(0, len(m.bulkPreviewLines())-m.bulkVisibleLines()))
+		case key.Matches(msg, Keys.Enter):
+			return m.applyBulkEdit()
+		}
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(msg, Keys.Escape):
+		m.bulkEdit = nil
+		return m, nil
+
+	case key.Matches(msg, Keys.Tab, Keys.NextField):
+		v.setFocus(v.focus + 1)
+		return m, nil
+
+	case key.Matches(msg, Keys.ShiftTab, Keys.PrevField):
+		v.setFocus(v.focus - 1)
+		return m, nil
+
+	case key.Matches(msg, Keys.Enter):
+		return m.previewBulkEdit()
+	}
+
+	if v.focus == bulkProvider {
+		n := len(config.Providers) + 1
+		switch {
+		case key.Matches(msg, Keys.Left):
+			v.provider = (v.provider - 1 + n) % n
+		case key.Matches(msg, Keys.Right, Keys.Space):
+			v.provider = (v.provider + 1) % n
+		}
+		return m, nil
+	}
+
+	newInput, cmd := v.inputs[v.focus].Update(msg)
+	v.inputs[v.focus] = newInput
+	return m, cmd
+}
+
+// previewBulkEdit computes what the edit would change on each selected model.
+func (m Model) previewBulkEdit() (tea.Model, tea.Cmd) {
+	v := m.bulkEdit
+	baseURL := strings.TrimSpace(v.inputs[bulkBaseURL].Value())
+	apiKey := strings.TrimSpace(v.inputs[bulkAPIKey].Value())
+	maxTokens := strings.TrimSpace(v.inputs[bulkMaxTokens].Value())
+
+	tokens := 0
+	if maxTokens != "" {
+		n, err := strconv.Atoi(maxTokens)
+		if err != nil || n < 0 {
+			m.status.SetError("Max tokens must be a non-negative number")
+			return m, statusClearCmd()
+		}
+		tokens = n
+	}
+	if baseURL == "" && apiKey == "" && maxTokens == "" && v.provider == 0 {
+		m.status.SetWarning("Fill in at least one field to change")
+		return m, statusClearCmd()
+	}
+
+	changes := make([]bulkChange, 0, len(v.indices))
+	for _, idx := range v.indices {
+		before := m.list.Items[idx].Model
+		after := before
+		if baseURL != "" {
+			after.BaseURL = baseURL
+		}
+		if apiKey != "" {
+			after.APIKey = apiKey
+		}
+		if v.provider > 0 {
+			after.Provider = config.Providers[v.provider-1]
+		}
+		if maxTokens != "" {
+			after.MaxTokens = tokens
+		}
+		changes = append(changes, bulkChange{index: idx, before: before, after: after, issues: newIssues(before, after)})
+	}
+	v.changes = changes
+	v.offset = 0
+	for i := range v.inputs {
+		v.inputs[i].Blur()
+	}
+	return m, nil
+}
+
+// newIssues returns the problems after has that before did not, so a model
+// that was already incomplete can still be bulk edited. Issues are matched by
+// field and severity, as messages can name the provider being changed.
+func newIssues(before, after config.CustomModel) config.Issues {
+	type issueKey struct {
+		field    config.Field
+		severity config.Severity
+	}
+	old := make(map[issueKey]bool)
+	for _, issue := range config.ValidateModel(before) {
+		old[issueKey{issue.Field, issue.Severity}] = true
+	}
+	var issues config.Issues
+	for _, issue := range config.ValidateModel(after) {
+		if !old[issueKey{issue.Field, issue.Severity}] {
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// bulkErrors counts the models the edit would make invalid.
+func (v *bulkEditView) bulkErrors() int {
+	n := 0
+	for _, c := range v.changes {
+		if c.issues.HasErrors() {
+			n++
+		}
+	}
+	return n
+}
+
+func (m Model) applyBulkEdit() (tea.Model, tea.Cmd) {
+	v := m.bulkEdit
+	if n := v.bulkErrors(); n > 0 {
+		m.status.SetError(fmt.Sprintf("The edit would make %d model(s) invalid - press %s to fix the fields", n, Keys.Escape.Help().Key))
+		return m, statusClearCmd()
+	}
+	m.history.push(m.snapshot())
+	for _, c := range v.changes {
+		m.list.SetModel(c.index, c.after)
+	}
+	m.loadCurrentModel()
+	m.bulkEdit = nil
+	m.dirty = true
+	m.status.SetSuccess(fmt.Sprintf("Updated %d model(s)", len(v.changes)))
+	return m.saveConfig()
+}
+
+// bulkPreviewLines lists the field changes per model, skipping fields that
+// already had the new value.
+func (m Model) bulkPreviewLines() []string {
+	changed := lipgloss.NewStyle().Foreground(warningColor)
+	var lines []string
+	if n := m.bulkEdit.bulkErrors(); n > 0 {
+		lines = append(lines, ValidationErrorStyle.Render(fmt.Sprintf("  The edit would make %d model(s) invalid; go back and fix the fields.", n)), "")
+	}
+	unchanged := 0
+	for _, c := range m.bulkEdit.changes {
+		var diffs []string
+		add := func(label, before, after string) {
+			if before != after {
+				diffs = append(diffs, fmt.Sprintf("    %-11s %s → %s", label+":", before, changed.Render(after)))
+			}
+		}
+		add("base_url", c.before.BaseURL, c.after.BaseURL)
+		add("api_key", config.MaskSecret(c.before.APIKey), config.MaskSecret(c.after.APIKey))
+		add("provider", c.before.Provider, c.after.Provider)
+		add("max_tokens", strconv.Itoa(c.before.MaxTokens), strconv.Itoa(c.after.MaxTokens))
+		for _, issue := range c.issues {
+			style := changed
+			if issue.Severity == config.SeverityError {
+				style = ValidationErrorStyle
+			}
+			diffs = append(diffs, style.Render(fmt.Sprintf("    %s: %s", issue.Severity, issue)))
+		}
+		if len(diffs) == 0 {
+			unchanged++
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("  %s %d. %s", ProviderBadges[c.before.Provider], c.index+1, c.before.DisplayName))
+		lines = append(lines, diffs...)
+	}
+	if unchanged > 0 {
+		lines = append(lines, HintStyle.Render(fmt.Sprintf("  %d model(s) already have these values", unchanged)))
+	}
+	return lines
+}
+
+// bulkVisibleLines is how many preview lines fit below the title and the
+// blank line of renderBulkEdit.
+func (m Model) bulkVisibleLines() int {
+	return max(1, m.contentHeight-2-2)
+}
+
+func (m Model) renderBulkEdit() string {
+	v := m.bulkEdit
+	width := max(1, m.width-4)
+	title := fmt.Sprintf("BULK EDIT %d SELECTED MODEL(S)", len(v.indices))
+
+	var lines []string
+	if v.previewing() {
+		lines = []string{
+			TitleBackgroundStyle.Render(padOrTruncate(title+" - PREVIEW", max(0, width-2))),
+			"",
+		}
+		preview := m.bulkPreviewLines()
+		end := min(len(preview), v.offset+m.bulkVisibleLines())
+		for _, line := range preview[min(v.offset, end):end] {
+			lines = append(lines, padOrTruncate(line, width))
+		}
+	} else {
+		lines = []string{
+			TitleBackgroundStyle.Render(padOrTruncate(title, max(0, width-2))),
+			HintStyle.Render(padOrTruncate("Empty fields are left unchanged.", width)),
+			"",
+		}
+		for field := 0; field < bulkFieldCount; field++ {
+			label := BlurredStyle.Render(bulkFieldLabels[field] + ":")
+			if field == v.focus {
+				label = FocusedStyle.Render(bulkFieldLabels[field] + ":")
+			}
+			var value string
+			if field == bulkProvider {
+				value = "unchanged"
+				if v.provider > 0 {
+					value = config.Providers[v.provider-1]
+				}
+				value = "← " + value + " →"
+				if field == v.focus {
+					value = FocusedStyle.Render(value)
+				}
+			} else {
+				v.inputs[field].Width = max(1, width-16-lipgloss.Width(v.inputs[field].Prompt)-1)
+				value = v.inputs[field].View()
+			}
+			lines = append(lines, padOrTruncate(lipgloss.NewStyle().Width(14).Render(label)+"  "+value, width), "")
+		}
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(primaryColor).
+		Width(max(0, m.width-2)).
+		Height(max(0, m.contentHeight-2)).
+		Padding(0, 1).
+		Render(strings.Join(lines, "\n"))
+}
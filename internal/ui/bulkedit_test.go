@@ -0,0 +1,23 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/diogo/droid-config/internal/config"
+)
+
+func TestNewIssuesIgnoresExistingProblems(t *testing.T) {
+	before := config.CustomModel{DisplayName: "A", Model: "m", APIKey: "sk-ant-1234567890", Provider: "openai"}
+	after := before
+	after.Provider = "anthropic"
+	if issues := newIssues(before, after); len(issues) > 0 {
+		t.Errorf("Expected the missing base URL not to count as new, got %v", issues)
+	}
+
+	before.BaseURL = "https://a.test/v1"
+	after = before
+	after.BaseURL = "a.test"
+	if issues := newIssues(before, after); !issues.HasErrors() {
+		t.Errorf("Expected the invalid base URL to be new, got %v", issues)
+	}
+}
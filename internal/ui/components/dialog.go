@@ -0,0 +1,297 @@
+package components
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Dialog layout
+const (
+	dialogButtonSpacing = 2 // columns between buttons
+	dialogPaddingX      = 2
+	dialogPaddingY      = 1
+	dialogBorderWidth   = 1
+	dialogDefaultTitle  = "CONFIRM"
+)
+
+var (
+	dialogColor         = lipgloss.Color("214")
+	dialogButtonStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("250")).Padding(0, 1)
+	dialogSelectedStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("0")).Background(dialogColor).Padding(0, 1)
+	dialogMessageStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("252"))
+	dialogTitleStyle    = lipgloss.NewStyle().Bold(true).Foreground(dialogColor)
+)
+
+// DialogButton is one of the choices a Dialog offers.
+type DialogButton struct {
+	Label  string
+	Key    key.Binding                // shortcut; ignored while a prompt has focus
+	Msg    func(input string) tea.Msg // sent when chosen, with the prompt text; nil only closes
+	Cancel bool                       // chosen by the cancel key
+}
+
+// DialogKeyMap holds the keys a Dialog handles besides button shortcuts.
+type DialogKeyMap struct {
+	Next   key.Binding // next button
+	Prev   key.Binding // previous button
+	Right  key.Binding // next button, unless a prompt has focus
+	Left   key.Binding // previous button, unless a prompt has focus
+	Choose key.Binding // chooses the selected button
+	Cancel key.Binding // chooses the cancel button, or only closes
+}
+
+// DefaultDialogKeyMap returns the keys a new Dialog uses.
+func DefaultDialogKeyMap() DialogKeyMap {
+	return DialogKeyMap{
+		Next:   key.NewBinding(key.WithKeys("tab")),
+		Prev:   key.NewBinding(key.WithKeys("shift+tab")),
+		Right:  key.NewBinding(key.WithKeys("right")),
+		Left:   key.NewBinding(key.WithKeys("left")),
+		Choose: key.NewBinding(key.WithKeys("enter")),
+		Cancel: key.NewBinding(key.WithKeys("esc")),
+	}
+}
+
+// Reply returns a DialogButton.Msg that always sends msg.
+func Reply(msg tea.Msg) func(string) tea.Msg {
+	return func(string) tea.Msg { return msg }
+}
+
+// buttonHit is where a button was last drawn, relative to the dialog.
+type buttonHit struct {
+	row, start, end int
+}
+
+// Dialog is a modal with a message, an optional text prompt and a row of
+// buttons. Choosing a button closes it and sends the button's message.
+type Dialog struct {
+	Active   bool
+	Title    string
+	Message  string
+	Buttons  []DialogButton
+	Selected int
+	Width    int // preferred width
+	MaxWidth int
+	Keys     DialogKeyMap
+
+	prompt bool
+	input  textinput.Model
+	hits   []buttonHit
+}
+
+func NewDialog() *Dialog {
+	input := textinput.New()
+	input.CharLimit = 256
+	return &Dialog{
+		Width:    40,
+		MaxWidth: 80,
+		Keys:     DefaultDialogKeyMap(),
+		input:    input,
+	}
+}
+
+// Show opens the dialog with the first button selected. An empty title
+// shows "CONFIRM".
+func (d *Dialog) Show(title, message string, buttons ...DialogButton) {
+	if title == "" {
+		title = dialogDefaultTitle
+	}
+	d.Active = true
+	d.Title = title
+	d.Message = message
+	d.Buttons = buttons
+	d.Selected = 0
+	d.prompt = false
+	d.input.Blur()
+}
+
+// Prompt opens the dialog with a text input holding value. Enter chooses the
+// selected button, so list the one that accepts the input first.
+func (d *Dialog) Prompt(title, message, value string, buttons ...DialogButton) tea.Cmd {
+	d.Show(title, message, buttons...)
+	d.prompt = true
+	d.input.SetValue(value)
+	d.input.CursorEnd()
+	return d.input.Focus()
+}
+
+func (d *Dialog) Hide() {
+	d.Active = false
+	d.input.Blur()
+}
+
+// Input returns the text of the prompt.
+func (d *Dialog) Input() string {
+	return d.input.Value()
+}
+
+// Update handles keys and mouse clicks while the dialog is active, and
+// passes anything else to the prompt.
+func (d *Dialog) Update(msg tea.Msg) tea.Cmd {
+	if !d.Active {
+		return nil
+	}
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		n := max(1, len(d.Buttons))
+		switch {
+		case key.Matches(msg, d.Keys.Next), !d.prompt && key.Matches(msg, d.Keys.Right):
+			d.Selected = (d.Selected + 1) % n
+			return nil
+		case key.Matches(msg, d.Keys.Prev), !d.prompt && key.Matches(msg, d.Keys.Left):
+			d.Selected = (d.Selected - 1 + n) % n
+			return nil
+		case key.Matches(msg, d.Keys.Choose):
+			return d.choose(d.Selected)
+		case key.Matches(msg, d.Keys.Cancel):
+			for i, b := range d.Buttons {
+				if b.Cancel {
+					return d.choose(i)
+				}
+			}
+			d.Hide()
+			return nil
+		}
+
+		if !d.prompt {
+			for i, b := range d.Buttons {
+				if key.Matches(msg, b.Key) {
+					return d.choose(i)
+				}
+			}
+			return nil
+		}
+	}
+
+	if d.prompt {
+		var cmd tea.Cmd
+		d.input, cmd = d.input.Update(msg)
+		return cmd
+	}
+	return nil
+}
+
+// HandleMouse chooses the button clicked. The dialog is assumed to be
+// centered on a screen of the given size, as View is placed by the caller.
+func (d *Dialog) HandleMouse(msg tea.MouseMsg, screenWidth, screenHeight int) tea.Cmd {
+	if !d.Active || msg.Button != tea.MouseButtonLeft || msg.Action != tea.MouseActionPress {
+		return nil
+	}
+	view := d.View()
+	left := (screenWidth - lipgloss.Width(view)) / 2
+	top := (screenHeight - lipgloss.Height(view)) / 2
+
+	return d.choose(d.ButtonAt(msg.X-left, msg.Y-top))
+}
+
+// ButtonAt returns the button drawn at x, y relative to the dialog's top-left
+// corner, or -1.
+func (d *Dialog) ButtonAt(x, y int) int {
+	for i, h := range d.hits {
+		if y == h.row && x >= h.start && x < h.end {
+			return i
+		}
+	}
+	return -1
+}
+
+func (d *Dialog) choose(i int) tea.Cmd {
+	if i < 0 || i >= len(d.Buttons) {
+		return nil
+	}
+	b := d.Buttons[i]
+	input := d.input.Value()
+	d.Hide()
+	if b.Msg == nil {
+		return nil
+	}
+	return func() tea.Msg { return b.Msg(input) }
+}
+
+func (d *Dialog) View() string {
+	if !d.Active {
+		return ""
+	}
+
+	labels := make([]string, len(d.Buttons))
+	buttonsWidth := 0
+	for i, b := range d.Buttons {
+		style := dialogButtonStyle
+		if i == d.Selected {
+			style = dialogSelectedStyle
+		}
+		labels[i] = style.Render(b.Label)
+		buttonsWidth += lipgloss.Width(labels[i]) + dialogButtonSpacing
+	}
+
+	messageWidth := 0
+	for _, line := range strings.Split(d.Message, "\n") {
+		messageWidth = max(messageWidth, lipgloss.Width(line))
+	}
+	width := max(d.Width, max(buttonsWidth, messageWidth)+2*dialogPaddingX)
+	if d.MaxWidth > 0 {
+		width = min(width, d.MaxWidth)
+	}
+	inner := max(1, width-2*dialogPaddingX)
+
+	lines := []string{lipgloss.PlaceHorizontal(inner, lipgloss.Center, dialogTitleStyle.Render(d.Title)), ""}
+	if d.Message != "" {
+		message := lipgloss.NewStyle().Width(inner).Align(lipgloss.Center).Render(dialogMessageStyle.Render(d.Message))
+		lines = append(lines, strings.Split(message, "\n")...)
+		lines = append(lines, "")
+	}
+	if d.prompt {
+		d.input.Width = max(1, inner-lipgloss.Width(d.input.Prompt)-1)
+		lines = append(lines, d.input.View(), "")
+	}
+
+	// Lay the buttons out in centered rows, remembering where each one lands
+	// so mouse clicks can be matched to it.
+	d.hits = make([]buttonHit, len(d.Buttons))
+	var row []int
+	rowWidth := 0
+	flush := func() {
+		if len(row) == 0 {
+			return
+		}
+		y := dialogBorderWidth + dialogPaddingY + len(lines)
+		x := dialogBorderWidth + dialogPaddingX + (inner-rowWidth)/2
+		parts := make([]string, len(row))
+		for j, i := range row {
+			w := lipgloss.Width(labels[i])
+			d.hits[i] = buttonHit{row: y, start: x, end: x + w}
+			x += w + dialogButtonSpacing
+			parts[j] = labels[i]
+		}
+		line := strings.Join(parts, strings.Repeat(" ", dialogButtonSpacing))
+		lines = append(lines, strings.Repeat(" ", (inner-rowWidth)/2)+line)
+		row, rowWidth = nil, 0
+	}
+	for i, label := range labels {
+		w := lipgloss.Width(label)
+		if len(row) > 0 && rowWidth+dialogButtonSpacing+w > inner {
+			flush()
+		}
+		if len(row) > 0 {
+			rowWidth += dialogButtonSpacing
+		}
+		row = append(row, i)
+		rowWidth += w
+	}
+	flush()
+
+	for i, line := range lines {
+		lines[i] = lipgloss.PlaceHorizontal(inner, lipgloss.Left, line)
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.DoubleBorder()).
+		BorderForeground(dialogColor).
+		Padding(dialogPaddingY, dialogPaddingX).
+		Render(strings.Join(lines, "\n"))
+}
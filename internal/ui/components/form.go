@@ -1,6 +1,10 @@
 package components
 
 import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -20,6 +24,16 @@ const (
 	FieldCount
 )
 
+// fieldNames are the labels of the form fields, indexed by field.
+var fieldNames = []string{
+	"Display Name",
+	"Model ID",
+	"Base URL",
+	"API Key",
+	"Provider",
+	"Max Tokens",
+}
+
 type Form struct {
 	inputs          []textinput.Model
 	providerIndex   int
@@ -27,7 +41,14 @@ type Form struct {
 	Width           int
 	Height          int
 	showAPIKey      bool
-	validationError map[int]string
+	validationError map[int]config.Issues
+	extra           map[string]json.RawMessage // unknown fields of the loaded model
+	loaded          [FieldCount]string         // field values as loaded or last saved
+
+	// Where the last View drew the fields, for mouse clicks.
+	spans          []fieldSpan
+	providerRow    int
+	providerArrows [2]int // columns of "<" and ">"
 }
 
 func NewForm() *Form {
@@ -38,7 +59,8 @@ func NewForm() *Form {
 		Width:           50,
 		Height:          20,
 		showAPIKey:      false,
-		validationError: make(map[int]string),
+		validationError: make(map[int]config.Issues),
+		providerRow:     -1,
 	}
 
 	for i := range f.inputs {
@@ -94,9 +116,13 @@ func (f *Form) LoadModel(m *config.CustomModel) {
 			f.inputs[i].SetValue("")
 		}
 		f.providerIndex = 0
+		f.extra = nil
+		f.MarkClean()
 		return
 	}
 
+	f.ClearValidationErrors()
+	f.extra = m.Extra()
 	f.inputs[FieldDisplayName].SetValue(m.DisplayName)
 	f.inputs[FieldModelID].SetValue(m.Model)
 	f.inputs[FieldBaseURL].SetValue(m.BaseURL)
@@ -113,6 +139,63 @@ func (f *Form) LoadModel(m *config.CustomModel) {
 			break
 		}
 	}
+	f.MarkClean()
+}
+
+// value returns the text of a field as the user sees it.
+func (f *Form) value(field int) string {
+	if field == FieldProvider {
+		if f.providerIndex >= 0 && f.providerIndex < len(config.Providers) {
+			return config.Providers[f.providerIndex]
+		}
+		return ""
+	}
+	return f.inputs[field].Value()
+}
+
+// MarkClean records the current values as saved, so no field is changed.
+func (f *Form) MarkClean() {
+	for i := range f.loaded {
+		f.loaded[i] = f.value(i)
+	}
+}
+
+// FieldChanged reports whether a field differs from the loaded model.
+func (f *Form) FieldChanged(field int) bool {
+	return field >= 0 && field < FieldCount && f.value(field) != f.loaded[field]
+}
+
+// Dirty reports whether any field differs from the loaded model.
+func (f *Form) Dirty() bool {
+	for i := 0; i < FieldCount; i++ {
+		if f.FieldChanged(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// Discard puts back the values of the loaded model.
+func (f *Form) Discard() {
+	for i := range f.inputs {
+		if i == FieldProvider {
+			continue
+		}
+		f.inputs[i].SetValue(f.loaded[i])
+	}
+	for i, p := range config.Providers {
+		if p == f.loaded[FieldProvider] {
+			f.providerIndex = i
+		}
+	}
+	f.ClearValidationErrors()
+}
+
+// SetFieldValue replaces the text of a single input field.
+func (f *Form) SetFieldValue(field int, value string) {
+	if field >= 0 && field < len(f.inputs) && field != FieldProvider {
+		f.inputs[field].SetValue(value)
+	}
 }
 
 func (f *Form) GetModel() config.CustomModel {
@@ -126,7 +209,7 @@ func (f *Form) GetModel() config.CustomModel {
 		provider = config.Providers[f.providerIndex]
 	}
 
-	return config.CustomModel{
+	m := config.CustomModel{
 		DisplayName: f.inputs[FieldDisplayName].Value(),
 		Model:       f.inputs[FieldModelID].Value(),
 		BaseURL:     f.inputs[FieldBaseURL].Value(),
@@ -134,26 +217,79 @@ func (f *Form) GetModel() config.CustomModel {
 		Provider:    provider,
 		MaxTokens:   maxTokens,
 	}
+	m.SetExtra(f.extra)
+	return m
+}
+
+// extraLines renders the unknown fields of the loaded model as compact
+// key: value lines, sorted by key.
+func (f *Form) extraLines() []string {
+	lines := make([]string, 0, len(f.extra))
+	for _, k := range slices.Sorted(maps.Keys(f.extra)) {
+		value := string(f.extra[k])
+		var b bytes.Buffer
+		if json.Compact(&b, f.extra[k]) == nil {
+			value = b.String()
+		}
+		lines = append(lines, k+": "+value)
+	}
+	return lines
 }
 
-func (f *Form) Validate() (bool, string) {
-	f.validationError = make(map[int]string)
+// configFields maps form fields to the config fields they edit.
+var configFields = map[config.Field]int{
+	config.FieldDisplayName: FieldDisplayName,
+	config.FieldModel:       FieldModelID,
+	config.FieldBaseURL:     FieldBaseURL,
+	config.FieldAPIKey:      FieldAPIKey,
+	config.FieldProvider:    FieldProvider,
+	config.FieldMaxTokens:   FieldMaxTokens,
+}
 
-	if f.inputs[FieldDisplayName].Value() == "" {
-		f.validationError[FieldDisplayName] = "Required"
-		return false, "Display Name is required"
+// Validate checks the form as if it replaced models[index] (or was appended
+// when index is out of range), so that cross-entry checks such as duplicate
+// names apply. Every issue is kept per field for View. It returns false with
+// a summary of the first error when saving should be refused, and the number
+// of warnings either way.
+func (f *Form) Validate(models []config.CustomModel, index int) (bool, string, int) {
+	f.validationError = make(map[int]config.Issues)
+
+	all := append([]config.CustomModel(nil), models...)
+	if index >= 0 && index < len(all) {
+		all[index] = f.GetModel()
+	} else {
+		index = len(all)
+		all = append(all, f.GetModel())
 	}
+	issues := config.ValidateModels(all)[index]
 
-	maxTokensStr := f.inputs[FieldMaxTokens].Value()
-	if maxTokensStr != "" {
-		v, err := strconv.Atoi(maxTokensStr)
-		if err != nil || v < 0 {
-			f.validationError[FieldMaxTokens] = "Must be positive integer"
-			return false, "Max Tokens must be a positive integer"
+	// GetModel drops an unparseable value, so check the raw text here.
+	if maxTokensStr := strings.TrimSpace(f.inputs[FieldMaxTokens].Value()); maxTokensStr != "" {
+		if _, err := strconv.Atoi(maxTokensStr); err != nil {
+			issues = append(issues, config.Issue{Field: config.FieldMaxTokens, Severity: config.SeverityError, Message: "must be a whole number"})
 		}
 	}
 
-	return true, ""
+	var errs []config.Issue
+	warnings := 0
+	for _, issue := range issues {
+		field := configFields[issue.Field]
+		f.validationError[field] = append(f.validationError[field], issue)
+		if issue.Severity == config.SeverityError {
+			errs = append(errs, issue)
+		} else {
+			warnings++
+		}
+	}
+	if len(errs) == 0 {
+		return true, "", warnings
+	}
+
+	msg := fieldNames[configFields[errs[0].Field]] + ": " + errs[0].Message
+	if len(errs) > 1 {
+		msg += " (+" + strconv.Itoa(len(errs)-1) + " more)"
+	}
+	return false, msg, warnings
 }
 
 func (f *Form) ToggleAPIKeyVisibility() {
@@ -166,7 +302,7 @@ func (f *Form) ToggleAPIKeyVisibility() {
 }
 
 func (f *Form) ClearValidationErrors() {
-	f.validationError = make(map[int]string)
+	f.validationError = make(map[int]config.Issues)
 }
 
 func (f *Form) Focus() {
@@ -244,13 +380,24 @@ func (f *Form) View(focused bool, modelName string) string {
 	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("252")).Bold(true)
 	inputBorder := lipgloss.RoundedBorder()
 	errorStyle := lipgloss.NewStyle().Foreground(errorColor).Bold(true)
+	warningStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
 	hintStyle := lipgloss.NewStyle().Foreground(dimmedColor).Italic(true)
+	refStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("141"))
+	modifiedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("81")).Bold(true)
 
 	panelWidth := f.Width
 	if panelWidth < 1 {
 		panelWidth = 1
 	}
 
+	// Key references are not secret themselves, so always show them.
+	keyKind, _ := config.ParseSecretRef(f.inputs[FieldAPIKey].Value())
+	if keyKind != config.SecretPlain || f.showAPIKey {
+		f.inputs[FieldAPIKey].EchoMode = textinput.EchoNormal
+	} else {
+		f.inputs[FieldAPIKey].EchoMode = textinput.EchoPassword
+	}
+
 	inputTextWidth := f.inputTextWidth()
 	for i := range f.inputs {
 		promptWidth := lipgloss.Width(f.inputs[i].Prompt)
@@ -261,23 +408,17 @@ func (f *Form) View(focused bool, modelName string) string {
 	if modelName != "" {
 		title = "EDITING: " + modelName
 	}
+	if f.Dirty() {
+		title += " (modified)"
+	}
 	titleContentWidth := max(0, panelWidth-2) // titleBackgroundStyle has horizontal padding=2
 	titleLine := titleBackgroundStyle.Render(padRight(title, titleContentWidth))
 
-	fieldLabels := []string{
-		"Display Name:",
-		"Model ID:",
-		"Base URL:",
-		"API Key:",
-		"Provider:",
-		"Max Tokens:",
-	}
-
 	fieldHints := []string{
 		"Name displayed in the UI",
-		"Identifier (e.g., gpt-4-turbo)",
+		"Identifier (Ctrl+L to pick from the provider)",
 		"API endpoint, usually ends in /v1",
-		"Provider API key (Ctrl+V to toggle)",
+		"Key, or env:VAR, file:PATH, cmd:COMMAND (Ctrl+V to toggle)",
 		"← → to switch providers",
 		"Maximum tokens per request",
 	}
@@ -301,9 +442,18 @@ func (f *Form) View(focused bool, modelName string) string {
 	for i := 0; i < FieldCount; i++ {
 		active := focused && f.focusIndex == i
 
-		label := fieldLabels[i]
-		if errMsg, hasErr := f.validationError[i]; hasErr && i != FieldProvider {
-			label += " " + errorStyle.Render("! "+errMsg)
+		label := fieldNames[i] + ":"
+		if f.FieldChanged(i) {
+			label += " " + modifiedStyle.Render("•")
+		}
+		if i == FieldAPIKey && keyKind != config.SecretPlain {
+			label += " " + refStyle.Render("("+keyKind.Describe()+" reference)")
+		}
+		issues := f.validationError[i]
+		if issues.HasErrors() {
+			label += " " + errorStyle.Render("!")
+		} else if len(issues) > 0 {
+			label += " " + warningStyle.Render("!")
 		}
 		labelLine := ansi.Truncate(labelStyle.Render(label), panelWidth, "")
 
@@ -314,25 +464,47 @@ func (f *Form) View(focused bool, modelName string) string {
 				provider = config.Providers[f.providerIndex]
 			}
 			providerText := padRight("< "+provider+" >", inputTextWidth)
-			box = inputStyleFor(active, false).Render(providerText)
+			box = inputStyleFor(active, issues.HasErrors()).Render(providerText)
 		} else {
-			box = inputStyleFor(active, false).Render(f.inputs[i].View())
+			box = inputStyleFor(active, issues.HasErrors()).Render(f.inputs[i].View())
 		}
 
 		block := []string{labelLine}
 		block = append(block, strings.Split(box, "\n")...)
+		for _, issue := range issues {
+			style := errorStyle
+			if issue.Severity == config.SeverityWarning {
+				style = warningStyle
+			}
+			block = append(block, style.Render(ansi.Truncate("  "+issue.Message, panelWidth, "…")))
+		}
 		if active {
 			block = append(block, hintStyle.Render(ansi.Truncate("  "+fieldHints[i], panelWidth, "")))
 		}
 		blocks = append(blocks, block)
 	}
 
+	// Fields this version does not know about cannot be edited here, but are
+	// shown so it is clear they will be kept.
+	if extra := f.extraLines(); len(extra) > 0 {
+		for i, line := range extra {
+			extra[i] = padRight(ansi.Truncate(line, inputTextWidth, "…"), inputTextWidth)
+		}
+		box := inputStyleFor(false, false).Foreground(dimmedColor).Render(strings.Join(extra, "\n"))
+		block := []string{ansi.Truncate(labelStyle.Render("Other Fields:"), panelWidth, "")}
+		block = append(block, strings.Split(box, "\n")...)
+		block = append(block, hintStyle.Render(ansi.Truncate("  Kept as is; edit the config file to change them", panelWidth, "")))
+		blocks = append(blocks, block)
+	}
+
 	header := []string{titleLine}
 	if f.Height >= 12 {
 		header = append(header, "")
 	}
 
 	if f.Height <= len(header) {
+		f.spans = f.spans[:0]
+		f.providerRow = -1
 		return strings.Join(header[:max(0, f.Height)], "\n")
 	}
 
@@ -342,8 +514,12 @@ func (f *Form) View(focused bool, modelName string) string {
 	}
 
 	// Add extra spacing between fields only when we can show the whole form comfortably.
+	total := len(blocks) - 1
+	for _, block := range blocks {
+		total += len(block)
+	}
 	fieldGap := 0
-	if f.Height >= 31 {
+	if f.Height >= 31 && total <= available {
 		fieldGap = 1
 	}
 
@@ -372,13 +548,30 @@ func (f *Form) View(focused bool, modelName string) string {
 		start = f.focusIndex
 	}
 
+	// The provider box is the label line followed by its border, so its
+	// text is on the third line of the block, after the border and padding.
+	providerLine := -1
+	arrowLeft := 2
+	arrowRight := arrowLeft + lipgloss.Width("< "+f.value(FieldProvider)+" ")
+
 	var body []string
+	f.spans = f.spans[:0]
+	addBlock := func(i int, block []string) {
+		top := len(header) + len(body)
+		if i < FieldCount {
+			f.spans = append(f.spans, fieldSpan{field: i, top: top, bottom: top + len(block)})
+		}
+		if i == FieldProvider && len(block) > 2 {
+			providerLine = top + 2
+		}
+		body = append(body, block...)
+	}
 	if end > start {
 		for i := start; i < end; i++ {
 			if i > start && fieldGap > 0 {
 				body = append(body, "")
 			}
-			body = append(body, blocks[i]...)
+			addBlock(i, blocks[i])
 		}
 	} else if available > 0 && f.focusIndex >= 0 && f.focusIndex < len(blocks) {
 		// Fallback: show as much as possible of the focused block.
@@ -386,8 +579,10 @@ func (f *Form) View(focused bool, modelName string) string {
 		if len(block) > available {
 			block = block[:available]
 		}
-		body = append(body, block...)
+		addBlock(f.focusIndex, block)
 	}
+	f.providerRow = providerLine
+	f.providerArrows = [2]int{arrowLeft, arrowRight}
 
 	return strings.Join(append(header, body...), "\n")
 }
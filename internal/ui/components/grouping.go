@@ -0,0 +1,196 @@
+package components
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// ViewMode controls how the list orders and groups its items on screen. It
+// never changes the order of Items; use ApplySort to persist an ordering.
+type ViewMode int
+
+const (
+	ViewFileOrder ViewMode = iota
+	ViewByName
+	ViewByModelID
+	ViewByProvider
+	ViewByHost
+)
+
+// ViewModes lists the modes in the order CycleViewMode steps through them.
+var ViewModes = []ViewMode{ViewFileOrder, ViewByName, ViewByModelID, ViewByProvider, ViewByHost}
+
+func (v ViewMode) String() string {
+	switch v {
+	case ViewByName:
+		return "sorted by name"
+	case ViewByModelID:
+		return "sorted by model ID"
+	case ViewByProvider:
+		return "grouped by provider"
+	case ViewByHost:
+		return "grouped by host"
+	}
+	return "file order"
+}
+
+// Grouped reports whether the mode shows collapsible group headers.
+func (v ViewMode) Grouped() bool {
+	return v == ViewByProvider || v == ViewByHost
+}
+
+// listRow is one line of the list body: either an item or a group header.
+type listRow struct {
+	item  int    // index into Items, or -1 for a group header
+	group string // group key of the item or header
+	size  int    // number of items under a header
+}
+
+func (r listRow) header() bool {
+	return r.item < 0
+}
+
+// groupKey returns the group an item belongs to under mode v.
+func (v ViewMode) groupKey(item ListItem) string {
+	switch v {
+	case ViewByProvider:
+		if item.Model.Provider == "" {
+			return "(no provider)"
+		}
+		return item.Model.Provider
+	case ViewByHost:
+		if u, err := url.Parse(item.Model.BaseURL); err == nil && u.Host != "" {
+			return u.Host
+		}
+		return "(no base URL)"
+	}
+	return ""
+}
+
+// sortKey returns the key items are ordered by under mode v.
+func (v ViewMode) sortKey(item ListItem) string {
+	switch v {
+	case ViewByName:
+		return strings.ToLower(itemLabel(item.Model))
+	case ViewByModelID:
+		return strings.ToLower(item.Model.Model)
+	case ViewByProvider, ViewByHost:
+		return strings.ToLower(v.groupKey(item))
+	}
+	return ""
+}
+
+// order sorts the item indices for display, keeping file order among equal
+// keys.
+func (v ViewMode) order(items []ListItem, indices []int) {
+	if v == ViewFileOrder {
+		return
+	}
+	sort.SliceStable(indices, func(a, b int) bool {
+		return v.sortKey(items[indices[a]]) < v.sortKey(items[indices[b]])
+	})
+}
+
+// buildRows lays out the given item indices, inserting a header before each
+// group and leaving out the items of collapsed groups.
+func (l *List) buildRows(indices []int) []listRow {
+	l.mode.order(l.Items, indices)
+
+	rows := make([]listRow, 0, len(indices))
+	if !l.mode.Grouped() {
+		for _, i := range indices {
+			rows = append(rows, listRow{item: i})
+		}
+		return rows
+	}
+
+	for start := 0; start < len(indices); {
+		group := l.mode.groupKey(l.Items[indices[start]])
+		end := start
+		for end < len(indices) && l.mode.groupKey(l.Items[indices[end]]) == group {
+			end++
+		}
+		rows = append(rows, listRow{item: -1, group: group, size: end - start})
+		if !l.collapsed[group] {
+			for _, i := range indices[start:end] {
+				rows = append(rows, listRow{item: i, group: group})
+			}
+		}
+		start = end
+	}
+	return rows
+}
+
+// ViewMode returns the current view mode.
+func (l *List) ViewMode() ViewMode {
+	return l.mode
+}
+
+// CycleViewMode switches to the next view mode and returns it.
+func (l *List) CycleViewMode() ViewMode {
+	for i, v := range ViewModes {
+		if v == l.mode {
+			l.mode = ViewModes[(i+1)%len(ViewModes)]
+			break
+		}
+	}
+	l.header = ""
+	l.refresh()
+	return l.mode
+}
+
+// OnHeader reports whether the cursor is on a group header.
+func (l *List) OnHeader() bool {
+	return l.header != ""
+}
+
+// ToggleGroup collapses or expands the group under the cursor and reports
+// whether there was one.
+func (l *List) ToggleGroup() bool {
+	if !l.mode.Grouped() {
+		return false
+	}
+	group := l.header
+	if group == "" {
+		if l.Cursor >= len(l.Items) {
+			return false
+		}
+		group = l.mode.groupKey(l.Items[l.Cursor])
+	}
+	l.collapsed[group] = !l.collapsed[group]
+	if l.collapsed[group] {
+		l.header = group
+	}
+	l.refresh()
+	return true
+}
+
+// ApplySort reorders Items the way the current view mode shows them and
+// returns to file order. It reports false in file order, where there is
+// nothing to apply.
+func (l *List) ApplySort() bool {
+	if l.mode == ViewFileOrder {
+		return false
+	}
+	indices := make([]int, len(l.Items))
+	for i := range indices {
+		indices[i] = i
+	}
+	l.mode.order(l.Items, indices)
+
+	items := make([]ListItem, len(l.Items))
+	cursor := l.Cursor
+	for pos, i := range indices {
+		items[pos] = l.Items[i]
+		if i == l.Cursor {
+			cursor = pos
+		}
+	}
+	l.Items = items
+	l.Cursor = cursor
+	l.mode = ViewFileOrder
+	l.header = ""
+	l.refresh()
+	return true
+}
@@ -0,0 +1,547 @@
+package components
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// JSONKind is the type of a JSON value.
+type JSONKind int
+
+const (
+	JSONNull JSONKind = iota
+	JSONBool
+	JSONNumber
+	JSONString
+	JSONArray
+	JSONObject
+)
+
+// Container reports whether values of the kind hold other values.
+func (k JSONKind) Container() bool {
+	return k == JSONArray || k == JSONObject
+}
+
+var (
+	treeKeyStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("252"))
+	treeStringStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("114"))
+	treeNumberStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("81"))
+	treeOtherStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("176"))
+	treeCountStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("242"))
+	treeCursorStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("0")).Background(lipgloss.Color("39"))
+)
+
+type jsonNode struct {
+	key      string // member name; empty for array elements and the root
+	kind     JSONKind
+	literal  string // JSON text of a scalar
+	children []*jsonNode
+	parent   *jsonNode
+	expanded bool
+
+	// Top-level nodes keep the text they were read from, so values that
+	// were not edited are written back unchanged.
+	raw     json.RawMessage
+	changed bool
+}
+
+// treeRow is a shown node and its nesting depth.
+type treeRow struct {
+	node  *jsonNode
+	depth int
+}
+
+// JSONTree browses and edits a set of named JSON values as a collapsible
+// tree. Member order within objects is kept.
+type JSONTree struct {
+	Cursor int // index into the shown rows
+	Height int
+	Width  int
+	offset int
+
+	root *jsonNode
+	rows []treeRow
+}
+
+// NewJSONTree builds a tree of values, with the top-level keys in the order
+// of keys.
+func NewJSONTree(values map[string]json.RawMessage, keys []string) (*JSONTree, error) {
+	root := &jsonNode{kind: JSONObject, expanded: true}
+	for _, key := range keys {
+		n, err := parseNode(values[key])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		n.key = key
+		n.parent = root
+		n.raw = values[key]
+		root.children = append(root.children, n)
+	}
+	t := &JSONTree{Height: 10, Width: 40, root: root}
+	t.refresh()
+	return t, nil
+}
+
+// Values returns the top-level values, re-encoding only those that changed.
+func (t *JSONTree) Values() map[string]json.RawMessage {
+	values := make(map[string]json.RawMessage, len(t.root.children))
+	for _, n := range t.root.children {
+		if n.changed || n.raw == nil {
+			var b bytes.Buffer
+			n.encode(&b)
+			values[n.key] = b.Bytes()
+		} else {
+			values[n.key] = n.raw
+		}
+	}
+	return values
+}
+
+// Empty reports whether the tree has no top-level values.
+func (t *JSONTree) Empty() bool {
+	return len(t.root.children) == 0
+}
+
+func (t *JSONTree) current() *jsonNode {
+	if t.Cursor < 0 || t.Cursor >= len(t.rows) {
+		return nil
+	}
+	return t.rows[t.Cursor].node
+}
+
+// CurrentKind returns the kind of the value under the cursor.
+func (t *JSONTree) CurrentKind() JSONKind {
+	if n := t.current(); n != nil {
+		return n.kind
+	}
+	return JSONNull
+}
+
+// CurrentPath returns the path of the value under the cursor, such as
+// telemetry.hosts[0].
+func (t *JSONTree) CurrentPath() string {
+	if n := t.current(); n != nil {
+		return t.pathOf(n)
+	}
+	return ""
+}
+
+func (t *JSONTree) pathOf(n *jsonNode) string {
+	var parts []string
+	for ; n != t.root; n = n.parent {
+		if n.parent.kind == JSONArray {
+			parts = append(parts, "["+strconv.Itoa(n.index())+"]")
+		} else {
+			parts = append(parts, n.key)
+			if n.parent != t.root {
+				parts = append(parts, ".")
+			}
+		}
+	}
+	var b strings.Builder
+	for i := len(parts) - 1; i >= 0; i-- {
+		b.WriteString(parts[i])
+	}
+	return b.String()
+}
+
+// CurrentJSON returns the value under the cursor as JSON, indented with
+// indent when it is a container.
+func (t *JSONTree) CurrentJSON(indent string) string {
+	n := t.current()
+	if n == nil {
+		return ""
+	}
+	var b bytes.Buffer
+	n.encode(&b)
+	if indent == "" || !n.kind.Container() {
+		return b.String()
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, b.Bytes(), "", indent); err != nil {
+		return b.String()
+	}
+	return out.String()
+}
+
+// AddTarget returns the path of the container Add inserts into, and whether
+// it is an array (whose elements need no key).
+func (t *JSONTree) AddTarget() (string, bool) {
+	target := t.addTarget()
+	if target == t.root {
+		return "", false
+	}
+	return t.pathOf(target), target.kind == JSONArray
+}
+
+// addTarget is the container under the cursor, or the one holding the value
+// under the cursor.
+func (t *JSONTree) addTarget() *jsonNode {
+	n := t.current()
+	switch {
+	case n == nil:
+		return t.root
+	case n.kind.Container():
+		return n
+	default:
+		return n.parent
+	}
+}
+
+// SetCurrent replaces the value under the cursor with data.
+func (t *JSONTree) SetCurrent(data []byte) error {
+	n := t.current()
+	if n == nil {
+		return errors.New("nothing selected")
+	}
+	repl, err := parseNode(data)
+	if err != nil {
+		return err
+	}
+	n.kind = repl.kind
+	n.literal = repl.literal
+	n.children = repl.children
+	for _, c := range n.children {
+		c.parent = n
+	}
+	n.top().changed = true
+	t.refresh()
+	return nil
+}
+
+// Add inserts data into the target container (see AddTarget), under key when
+// it is an object, and moves the cursor to it.
+func (t *JSONTree) Add(key string, data []byte) error {
+	if err := t.CanAdd(key); err != nil {
+		return err
+	}
+	target := t.addTarget()
+	if target.kind != JSONObject {
+		key = ""
+	}
+
+	n, err := parseNode(data)
+	if err != nil {
+		return err
+	}
+	n.key = key
+	n.parent = target
+	target.children = append(target.children, n)
+	target.expanded = true
+	n.top().changed = true
+
+	t.refresh()
+	for i, r := range t.rows {
+		if r.node == n {
+			t.Cursor = i
+		}
+	}
+	t.ensureVisible()
+	return nil
+}
+
+// CanAdd checks that key can name a new member of the target container.
+// Array elements need no key.
+func (t *JSONTree) CanAdd(key string) error {
+	target := t.addTarget()
+	if target.kind != JSONObject {
+		return nil
+	}
+	if key == "" {
+		return errors.New("key is required")
+	}
+	for _, c := range target.children {
+		if c.key == key {
+			return fmt.Errorf("key %q already exists", key)
+		}
+	}
+	if target == t.root && key == "custom_models" {
+		return errors.New("custom_models is edited in the model list")
+	}
+	return nil
+}
+
+// DeleteCurrent removes the value under the cursor.
+func (t *JSONTree) DeleteCurrent() bool {
+	n := t.current()
+	if n == nil {
+		return false
+	}
+	parent := n.parent
+	i := n.index()
+	parent.children = append(parent.children[:i], parent.children[i+1:]...)
+	if parent != t.root {
+		parent.top().changed = true
+	}
+	t.refresh()
+	return true
+}
+
+func (t *JSONTree) MoveUp() {
+	if t.Cursor > 0 {
+		t.Cursor--
+		t.ensureVisible()
+	}
+}
+
+func (t *JSONTree) MoveDown() {
+	if t.Cursor < len(t.rows)-1 {
+		t.Cursor++
+		t.ensureVisible()
+	}
+}
+
+// Toggle expands or collapses the container under the cursor. It returns
+// false for scalars.
+func (t *JSONTree) Toggle() bool {
+	n := t.current()
+	if n == nil || !n.kind.Container() {
+		return false
+	}
+	n.expanded = !n.expanded
+	t.refresh()
+	return true
+}
+
+// Expand opens the container under the cursor, or steps into it when it is
+// already open.
+func (t *JSONTree) Expand() {
+	n := t.current()
+	if n == nil || !n.kind.Container() {
+		return
+	}
+	if !n.expanded {
+		n.expanded = true
+		t.refresh()
+	} else if len(n.children) > 0 {
+		t.MoveDown()
+	}
+}
+
+// Collapse closes the container under the cursor, or moves to the parent.
+func (t *JSONTree) Collapse() {
+	n := t.current()
+	if n == nil {
+		return
+	}
+	if n.kind.Container() && n.expanded {
+		n.expanded = false
+		t.refresh()
+		return
+	}
+	if n.parent == t.root {
+		return
+	}
+	for i, r := range t.rows {
+		if r.node == n.parent {
+			t.Cursor = i
+		}
+	}
+	t.ensureVisible()
+}
+
+// refresh rebuilds the shown rows and keeps the cursor in range.
+func (t *JSONTree) refresh() {
+	t.rows = t.rows[:0]
+	var walk func(n *jsonNode, depth int)
+	walk = func(n *jsonNode, depth int) {
+		for _, c := range n.children {
+			t.rows = append(t.rows, treeRow{node: c, depth: depth})
+			if c.kind.Container() && c.expanded {
+				walk(c, depth+1)
+			}
+		}
+	}
+	walk(t.root, 0)
+	t.ensureVisible()
+}
+
+func (t *JSONTree) ensureVisible() {
+	t.Cursor = max(0, min(t.Cursor, len(t.rows)-1))
+	visible := max(1, t.Height)
+	if t.Cursor < t.offset {
+		t.offset = t.Cursor
+	}
+	if t.Cursor >= t.offset+visible {
+		t.offset = t.Cursor - visible + 1
+	}
+	t.offset = max(0, min(t.offset, len(t.rows)-visible))
+}
+
+// View renders the shown rows that fit in Height.
+func (t *JSONTree) View(focused bool) string {
+	t.ensureVisible()
+	end := min(len(t.rows), t.offset+max(1, t.Height))
+
+	var lines []string
+	for i := t.offset; i < end; i++ {
+		r := t.rows[i]
+		indent := strings.Repeat("  ", r.depth)
+		if focused && i == t.Cursor {
+			lines = append(lines, treeCursorStyle.Render(padOrTruncate(indent+rowText(r.node, false), t.Width)))
+		} else {
+			lines = append(lines, padOrTruncate(indent+rowText(r.node, true), t.Width))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// rowText renders a node as "key: value" for scalars and "▾ key {n}" for
+// containers, colored when styled is set.
+func rowText(n *jsonNode, styled bool) string {
+	render := func(style lipgloss.Style, s string) string {
+		if styled {
+			return style.Render(s)
+		}
+		return s
+	}
+
+	label := n.key
+	if n.parent.kind == JSONArray {
+		label = "[" + strconv.Itoa(n.index()) + "]"
+	}
+	if !n.kind.Container() {
+		return "  " + render(treeKeyStyle, label) + ": " + render(literalStyle(n.kind), n.literal)
+	}
+
+	marker, count := "▸ ", "{"+strconv.Itoa(len(n.children))+"}"
+	if n.expanded {
+		marker = "▾ "
+	}
+	if n.kind == JSONArray {
+		count = "[" + strconv.Itoa(len(n.children)) + "]"
+	}
+	return marker + render(treeKeyStyle, label) + " " + render(treeCountStyle, count)
+}
+
+func literalStyle(kind JSONKind) lipgloss.Style {
+	switch kind {
+	case JSONString:
+		return treeStringStyle
+	case JSONNumber:
+		return treeNumberStyle
+	default:
+		return treeOtherStyle
+	}
+}
+
+// top returns the top-level node n belongs to.
+func (n *jsonNode) top() *jsonNode {
+	for n.parent != nil && n.parent.parent != nil {
+		n = n.parent
+	}
+	return n
+}
+
+func (n *jsonNode) index() int {
+	for i, c := range n.parent.children {
+		if c == n {
+			return i
+		}
+	}
+	return -1
+}
+
+func (n *jsonNode) encode(b *bytes.Buffer) {
+	switch n.kind {
+	case JSONObject:
+		b.WriteByte('{')
+		for i, c := range n.children {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(quoteJSON(c.key))
+			b.WriteByte(':')
+			c.encode(b)
+		}
+		b.WriteByte('}')
+	case JSONArray:
+		b.WriteByte('[')
+		for i, c := range n.children {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			c.encode(b)
+		}
+		b.WriteByte(']')
+	default:
+		b.WriteString(n.literal)
+	}
+}
+
+// parseNode reads a single JSON value, keeping object member order.
+func parseNode(data []byte) (*jsonNode, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	n, err := decodeNode(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after the value")
+	}
+	return n, nil
+}
+
+func decodeNode(dec *json.Decoder) (*jsonNode, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		if err == io.EOF {
+			return nil, errors.New("value is empty")
+		}
+		return nil, err
+	}
+
+	switch v := tok.(type) {
+	case json.Delim:
+		n := &jsonNode{kind: JSONObject}
+		if v == '[' {
+			n.kind = JSONArray
+		}
+		for dec.More() {
+			var key string
+			if n.kind == JSONObject {
+				tok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key, _ = tok.(string)
+			}
+			c, err := decodeNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			c.key = key
+			c.parent = n
+			n.children = append(n.children, c)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return n, nil
+	case string:
+		return &jsonNode{kind: JSONString, literal: quoteJSON(v)}, nil
+	case json.Number:
+		return &jsonNode{kind: JSONNumber, literal: v.String()}, nil
+	case bool:
+		return &jsonNode{kind: JSONBool, literal: strconv.FormatBool(v)}, nil
+	default:
+		return &jsonNode{kind: JSONNull, literal: "null"}, nil
+	}
+}
+
+// quoteJSON encodes s as a JSON string without escaping HTML characters.
+func quoteJSON(s string) string {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
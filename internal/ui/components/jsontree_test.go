@@ -0,0 +1,210 @@
+package components
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func newTestTree(t *testing.T) *JSONTree {
+	t.Helper()
+	values := map[string]json.RawMessage{
+		"telemetry": json.RawMessage(`{"enabled": true, "hosts": ["a", {"x": 1}]}`),
+		"theme":     json.RawMessage(`"dark"`),
+	}
+	tree, err := NewJSONTree(values, []string{"theme", "telemetry"})
+	if err != nil {
+		t.Fatalf("NewJSONTree failed: %v", err)
+	}
+	return tree
+}
+
+// moveTo puts the cursor on the shown row with the given path.
+func moveTo(t *testing.T, tree *JSONTree, path string) {
+	t.Helper()
+	for tree.Cursor = 0; tree.Cursor < len(tree.rows); tree.Cursor++ {
+		if tree.CurrentPath() == path {
+			return
+		}
+	}
+	t.Fatalf("No shown row %q", path)
+}
+
+func TestJSONTreeKeepsUnchangedValues(t *testing.T) {
+	tree := newTestTree(t)
+
+	values := tree.Values()
+	if string(values["telemetry"]) != `{"enabled": true, "hosts": ["a", {"x": 1}]}` || string(values["theme"]) != `"dark"` {
+		t.Errorf("Expected the values as read, got %s", values)
+	}
+	if tree.CurrentPath() != "theme" {
+		t.Errorf("Expected top-level keys in the given order, got %q first", tree.CurrentPath())
+	}
+}
+
+func TestJSONTreeRejectsInvalidValues(t *testing.T) {
+	if _, err := NewJSONTree(map[string]json.RawMessage{"a": json.RawMessage(`{"b":`)}, []string{"a"}); err == nil || !strings.HasPrefix(err.Error(), "a: ") {
+		t.Errorf("Expected an error naming the key, got %v", err)
+	}
+
+	tree := newTestTree(t)
+	for _, data := range []string{"", "{", "1 2", "nope"} {
+		if err := tree.SetCurrent([]byte(data)); err == nil {
+			t.Errorf("Expected %q to be rejected", data)
+		}
+	}
+	if string(tree.Values()["theme"]) != `"dark"` {
+		t.Errorf("Expected a rejected edit to leave the value alone, got %s", tree.Values()["theme"])
+	}
+}
+
+func TestJSONTreeEditNested(t *testing.T) {
+	tree := newTestTree(t)
+
+	moveTo(t, tree, "telemetry")
+	tree.Expand()
+	moveTo(t, tree, "telemetry.hosts")
+	tree.Expand()
+	moveTo(t, tree, "telemetry.hosts[1]")
+	tree.Expand()
+	moveTo(t, tree, "telemetry.hosts[1].x")
+	if err := tree.SetCurrent([]byte(`[true, null, "<b>"]`)); err != nil {
+		t.Fatalf("SetCurrent failed: %v", err)
+	}
+	if tree.CurrentKind() != JSONArray || tree.CurrentJSON("") != `[true,null,"<b>"]` {
+		t.Errorf("Expected the new array under the cursor, got %s", tree.CurrentJSON(""))
+	}
+
+	values := tree.Values()
+	if got := string(values["telemetry"]); got != `{"enabled":true,"hosts":["a",{"x":[true,null,"<b>"]}]}` {
+		t.Errorf("Expected the edit with member order kept, got %s", got)
+	}
+	if string(values["theme"]) != `"dark"` {
+		t.Errorf("Expected the unedited value as read, got %s", values["theme"])
+	}
+}
+
+func TestJSONTreeAdd(t *testing.T) {
+	tree := newTestTree(t)
+
+	if err := tree.CanAdd("custom_models"); err == nil {
+		t.Error("Expected custom_models to be refused at the top level")
+	}
+	if err := tree.CanAdd("theme"); err == nil {
+		t.Error("Expected an existing key to be refused")
+	}
+	if err := tree.Add("", []byte(`1`)); err == nil {
+		t.Error("Expected a missing key to be refused")
+	}
+	if err := tree.Add("zoom", []byte(`1.5`)); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if tree.CurrentPath() != "zoom" {
+		t.Errorf("Expected the cursor on the new value, got %q", tree.CurrentPath())
+	}
+
+	// A scalar in an array adds to the array, without a key.
+	moveTo(t, tree, "telemetry")
+	tree.Expand()
+	moveTo(t, tree, "telemetry.hosts")
+	tree.Expand()
+	moveTo(t, tree, "telemetry.hosts[0]")
+	if path, array := tree.AddTarget(); path != "telemetry.hosts" || !array {
+		t.Errorf("Expected to add to the hosts array, got %q (array %v)", path, array)
+	}
+	if err := tree.Add("ignored", []byte(`{"b": 2, "a": 1}`)); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if tree.CurrentPath() != "telemetry.hosts[2]" {
+		t.Errorf("Expected the cursor on the new element, got %q", tree.CurrentPath())
+	}
+
+	// The new object is now the target.
+	if err := tree.CanAdd("a"); err == nil {
+		t.Error("Expected a duplicate member to be refused")
+	}
+	if err := tree.Add("c", []byte(`[]`)); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	values := tree.Values()
+	if got := string(values["telemetry"]); got != `{"enabled":true,"hosts":["a",{"x":1},{"b":2,"a":1,"c":[]}]}` {
+		t.Errorf("Expected members appended in order, got %s", got)
+	}
+	if string(values["zoom"]) != `1.5` {
+		t.Errorf("Expected the new top-level value, got %s", values["zoom"])
+	}
+}
+
+func TestJSONTreeDelete(t *testing.T) {
+	tree := newTestTree(t)
+
+	moveTo(t, tree, "telemetry")
+	tree.Expand()
+	moveTo(t, tree, "telemetry.hosts")
+	tree.Expand()
+	moveTo(t, tree, "telemetry.hosts[0]")
+	if !tree.DeleteCurrent() {
+		t.Fatal("DeleteCurrent failed")
+	}
+	if tree.CurrentPath() != "telemetry.hosts[0]" || tree.CurrentKind() != JSONObject {
+		t.Errorf("Expected the next element to move up, got %q", tree.CurrentPath())
+	}
+	if got := string(tree.Values()["telemetry"]); got != `{"enabled":true,"hosts":[{"x":1}]}` {
+		t.Errorf("Expected the element removed, got %s", got)
+	}
+
+	moveTo(t, tree, "theme")
+	tree.DeleteCurrent()
+	if _, ok := tree.Values()["theme"]; ok {
+		t.Error("Expected the deleted top-level value to be gone")
+	}
+
+	moveTo(t, tree, "telemetry")
+	tree.DeleteCurrent()
+	if !tree.Empty() || tree.DeleteCurrent() {
+		t.Errorf("Expected an empty tree, got %s", tree.Values())
+	}
+}
+
+func TestJSONTreeExpandCollapse(t *testing.T) {
+	tree := newTestTree(t)
+	if len(tree.rows) != 2 {
+		t.Fatalf("Expected containers to start collapsed, got %d rows", len(tree.rows))
+	}
+
+	moveTo(t, tree, "theme")
+	if tree.Toggle() {
+		t.Error("Expected Toggle to refuse a scalar")
+	}
+
+	moveTo(t, tree, "telemetry")
+	tree.Expand()
+	if len(tree.rows) != 4 || tree.CurrentPath() != "telemetry" {
+		t.Fatalf("Expected telemetry opened in place, got %d rows at %q", len(tree.rows), tree.CurrentPath())
+	}
+	tree.Expand()
+	if tree.CurrentPath() != "telemetry.enabled" {
+		t.Errorf("Expected Expand on an open container to step in, got %q", tree.CurrentPath())
+	}
+
+	moveTo(t, tree, "telemetry.hosts")
+	tree.Expand()
+	moveTo(t, tree, "telemetry.hosts[1]")
+	tree.Collapse()
+	if tree.CurrentPath() != "telemetry.hosts" {
+		t.Errorf("Expected Collapse on a closed container to move to the parent, got %q", tree.CurrentPath())
+	}
+	tree.Collapse()
+	if len(tree.rows) != 4 || tree.CurrentPath() != "telemetry.hosts" {
+		t.Errorf("Expected hosts closed with the cursor kept, got %d rows at %q", len(tree.rows), tree.CurrentPath())
+	}
+
+	// Closing a container above the cursor keeps the cursor in range.
+	tree.Cursor = len(tree.rows) - 1
+	moveTo(t, tree, "telemetry")
+	tree.Toggle()
+	if len(tree.rows) != 2 || tree.Cursor >= len(tree.rows) {
+		t.Errorf("Expected 2 rows with the cursor on one, got %d rows, cursor %d", len(tree.rows), tree.Cursor)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/x/ansi"
 	"github.com/diogo/droid-config/internal/config"
@@ -32,6 +33,8 @@ var (
 	edgeIndicatorUp    = "▲"
 	edgeIndicatorDown  = "▼"
 	edgeIndicatorStyle = lipgloss.NewStyle().Foreground(scrollbarSecondaryColor)
+
+	groupHeaderStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("111"))
 )
 
 type ListItem struct {
@@ -41,10 +44,22 @@ type ListItem struct {
 
 type List struct {
 	Items  []ListItem
-	Cursor int
+	Cursor int // index into Items, also while a filter hides some of them
 	Height int
 	Width  int
 	offset int
+
+	rows      []listRow // shown rows in display order
+	search    textinput.Model
+	searching bool
+	filter    string
+	matches   map[int][]int // item index -> matched rune positions in its label
+	mode      ViewMode
+	collapsed map[string]bool
+	header    string // group whose header the cursor is on, if any
+
+	dragging   bool // the scrollbar thumb is being dragged
+	dragAnchor int  // line of the thumb that was grabbed
 }
 
 func padOrTruncate(s string, width int) string {
@@ -59,18 +74,24 @@ func padOrTruncate(s string, width int) string {
 }
 
 func NewList() *List {
+	search := textinput.New()
+	search.Prompt = "/"
+	search.Placeholder = "search"
+	search.CharLimit = 128
 	return &List{
-		Items:  []ListItem{},
-		Cursor: 0,
-		Height: 10,
-		Width:  25,
+		Items:     []ListItem{},
+		Cursor:    0,
+		Height:    10,
+		Width:     25,
+		search:    search,
+		collapsed: make(map[string]bool),
 	}
 }
 
 // getVisibleHeight returns the number of items that can be displayed
 func (l *List) getVisibleHeight() int {
 	visible := l.Height - l.headerLinesToShow() - listFooterLines
-	if len(l.Items) > 0 && visible < 1 && l.Height > 1 {
+	if len(l.rows) > 0 && visible < 1 && l.Height > 1 {
 		visible = 1
 	}
 	if visible < 0 {
@@ -105,6 +126,7 @@ func (l *List) SetItems(models []config.CustomModel) {
 	if l.Cursor >= len(l.Items) {
 		l.Cursor = max(0, len(l.Items)-1)
 	}
+	l.refresh()
 }
 
 func (l *List) GetModels() []config.CustomModel {
@@ -115,22 +137,120 @@ func (l *List) GetModels() []config.CustomModel {
 	return models
 }
 
+// SetCursor moves the cursor to index i, clamped to the list bounds.
+func (l *List) SetCursor(i int) {
+	l.Cursor = max(0, min(i, len(l.Items)-1))
+	l.header = ""
+	l.refresh()
+}
+
+// refresh recomputes the shown rows after the items, the filter or the view
+// mode changed and moves the cursor onto a shown row if it was hidden.
+func (l *List) refresh() {
+	l.matches = make(map[int][]int)
+	indices := make([]int, 0, len(l.Items))
+	for i, item := range l.Items {
+		if l.filter == "" {
+			indices = append(indices, i)
+			continue
+		}
+		if positions, ok := matchModel(l.filter, item.Model); ok {
+			indices = append(indices, i)
+			l.matches[i] = positions
+		}
+	}
+	l.rows = l.buildRows(indices)
+
+	if l.header != "" && l.headerRow(l.header) < 0 {
+		l.header = ""
+	}
+	if len(l.rows) > 0 && l.cursorRow() < 0 {
+		// The cursor item is hidden: land on its collapsed group header if it
+		// has one, otherwise on the next shown item.
+		if l.mode.Grouped() && l.Cursor < len(l.Items) {
+			if group := l.mode.groupKey(l.Items[l.Cursor]); l.collapsed[group] && l.headerRow(group) >= 0 {
+				l.header = group
+			}
+		}
+		if l.header == "" {
+			l.setRow(l.nearestRow())
+		}
+	}
+	l.updateOffset()
+}
+
+// nearestRow picks the row to show when the cursor item disappeared: the
+// first item after it in file order, or the last row.
+func (l *List) nearestRow() int {
+	best := -1
+	for row, r := range l.rows {
+		if !r.header() && r.item >= l.Cursor && (best < 0 || r.item < l.rows[best].item) {
+			best = row
+		}
+	}
+	if best < 0 {
+		best = len(l.rows) - 1
+	}
+	return best
+}
+
+// rowOf returns the display row of item idx, or -1 when it is hidden.
+func (l *List) rowOf(idx int) int {
+	for row, r := range l.rows {
+		if r.item == idx {
+			return row
+		}
+	}
+	return -1
+}
+
+// headerRow returns the display row of the header of group, or -1.
+func (l *List) headerRow(group string) int {
+	for row, r := range l.rows {
+		if r.header() && r.group == group {
+			return row
+		}
+	}
+	return -1
+}
+
+// cursorRow returns the display row under the cursor, or -1 when hidden.
+func (l *List) cursorRow() int {
+	if l.header != "" {
+		return l.headerRow(l.header)
+	}
+	return l.rowOf(l.Cursor)
+}
+
+// setRow moves the cursor to a display row.
+func (l *List) setRow(row int) {
+	if row < 0 || row >= len(l.rows) {
+		return
+	}
+	if r := l.rows[row]; r.header() {
+		l.header = r.group
+	} else {
+		l.header = ""
+		l.Cursor = r.item
+	}
+	l.updateOffset()
+}
+
 func (l *List) MoveUp() {
-	if l.Cursor > 0 {
-		l.Cursor--
-		l.updateOffset()
+	if row := l.cursorRow(); row > 0 {
+		l.setRow(row - 1)
 	}
 }
 
 func (l *List) MoveDown() {
-	if l.Cursor < len(l.Items)-1 {
-		l.Cursor++
-		l.updateOffset()
+	if row := l.cursorRow(); row >= 0 && row < len(l.rows)-1 {
+		l.setRow(row + 1)
 	}
 }
 
 func (l *List) updateOffset() {
 	visibleHeight := l.getVisibleHeight()
+	cursorRow := max(0, l.cursorRow())
 
 	// Padded scrolling: keep cursor away from edges when possible
 	padding := scrollPadding
@@ -139,17 +259,17 @@ func (l *List) updateOffset() {
 	}
 
 	// Scroll up: maintain padding from top
-	if l.Cursor < l.offset+padding {
-		l.offset = max(0, l.Cursor-padding)
+	if cursorRow < l.offset+padding {
+		l.offset = max(0, cursorRow-padding)
 	}
 
 	// Scroll down: maintain padding from bottom
-	if l.Cursor >= l.offset+visibleHeight-padding {
-		l.offset = l.Cursor - visibleHeight + padding + 1
+	if cursorRow >= l.offset+visibleHeight-padding {
+		l.offset = cursorRow - visibleHeight + padding + 1
 	}
 
 	// Clamp offset to valid range
-	maxOffset := max(0, len(l.Items)-visibleHeight)
+	maxOffset := max(0, len(l.rows)-visibleHeight)
 	if l.offset > maxOffset {
 		l.offset = maxOffset
 	}
@@ -158,29 +278,51 @@ func (l *List) updateOffset() {
 	}
 }
 
+// ToggleSelected toggles the item under the cursor, or every item of the
+// group when the cursor is on a group header.
 func (l *List) ToggleSelected() {
+	if l.header != "" {
+		all := true
+		for i, item := range l.Items {
+			if l.mode.groupKey(item) == l.header && !l.Items[i].Selected {
+				all = false
+			}
+		}
+		for i, item := range l.Items {
+			if l.mode.groupKey(item) == l.header {
+				l.Items[i].Selected = !all
+			}
+		}
+		return
+	}
 	if l.Cursor < len(l.Items) {
 		l.Items[l.Cursor].Selected = !l.Items[l.Cursor].Selected
 	}
 }
 
+// SelectAll toggles the selection of every shown item.
 func (l *List) SelectAll() {
 	allSelected := l.AllSelected()
-	for i := range l.Items {
-		l.Items[i].Selected = !allSelected
+	for _, r := range l.rows {
+		if !r.header() {
+			l.Items[r.item].Selected = !allSelected
+		}
 	}
 }
 
+// AllSelected reports whether every shown item is selected.
 func (l *List) AllSelected() bool {
-	if len(l.Items) == 0 {
-		return false
-	}
-	for _, item := range l.Items {
-		if !item.Selected {
+	shown := false
+	for _, r := range l.rows {
+		if r.header() {
+			continue
+		}
+		if !l.Items[r.item].Selected {
 			return false
 		}
+		shown = true
 	}
-	return true
+	return shown
 }
 
 func (l *List) GetSelectedIndices() []int {
@@ -199,23 +341,112 @@ func (l *List) ClearSelections() {
 	}
 }
 
+// CurrentModel returns the model under the cursor, or nil when the list is
+// empty, the filter hides every item or the cursor is on a group header.
 func (l *List) CurrentModel() *config.CustomModel {
-	if l.Cursor >= 0 && l.Cursor < len(l.Items) {
+	if l.header == "" && l.Cursor >= 0 && l.Cursor < len(l.Items) && l.rowOf(l.Cursor) >= 0 {
 		return &l.Items[l.Cursor].Model
 	}
 	return nil
 }
 
 func (l *List) UpdateCurrentModel(m config.CustomModel) {
-	if l.Cursor >= 0 && l.Cursor < len(l.Items) {
-		l.Items[l.Cursor].Model = m
+	if l.CurrentModel() == nil {
+		return
+	}
+	l.Items[l.Cursor].Model = m
+	if l.mode != ViewFileOrder {
+		// The edit may move the item to another position or group.
+		delete(l.collapsed, l.mode.groupKey(l.Items[l.Cursor]))
+		l.refresh()
+		return
+	}
+	// Keep the edited item shown even if it no longer matches the filter.
+	if l.filter != "" {
+		positions, _ := matchModel(l.filter, m)
+		l.matches[l.Cursor] = positions
 	}
 }
 
+// SetModel replaces the model at index i.
+func (l *List) SetModel(i int, m config.CustomModel) {
+	if i < 0 || i >= len(l.Items) {
+		return
+	}
+	l.Items[i].Model = m
+	l.refresh()
+}
+
 func (l *List) AddModel(m config.CustomModel) {
 	l.Items = append(l.Items, ListItem{Model: m, Selected: false})
 	l.Cursor = len(l.Items) - 1
-	l.updateOffset()
+	l.header = ""
+	if l.mode.Grouped() {
+		delete(l.collapsed, l.mode.groupKey(l.Items[l.Cursor]))
+	}
+	l.refresh()
+}
+
+// CloneSelected inserts a copy of every selected item directly below it, or
+// of the current item when nothing is selected, and returns how many copies
+// were made. Copies get " (copy)", or " (copy 2)" and so on when that name is
+// taken, appended to their display name; the cursor
+// moves to the copy of the current item, or to the first copy.
+func (l *List) CloneSelected() int {
+	indices := l.GetSelectedIndices()
+	if len(indices) == 0 {
+		if l.CurrentModel() == nil {
+			return 0
+		}
+		indices = []int{l.Cursor}
+	}
+	clone := make(map[int]bool, len(indices))
+	for _, idx := range indices {
+		clone[idx] = true
+	}
+	taken := make(map[string]bool, len(l.Items)+len(indices))
+	for _, item := range l.Items {
+		taken[nameKey(item.Model.DisplayName)] = true
+	}
+
+	items := make([]ListItem, 0, len(l.Items)+len(indices))
+	cursor := -1
+	for i, item := range l.Items {
+		item.Selected = false
+		items = append(items, item)
+		if !clone[i] {
+			continue
+		}
+		items = append(items, ListItem{Model: cloneModel(item.Model, taken)})
+		if cursor < 0 || i == l.Cursor {
+			cursor = len(items) - 1
+		}
+	}
+
+	l.Items = items
+	l.Cursor = cursor
+	l.header = ""
+	l.refresh()
+	return len(indices)
+}
+
+// cloneModel copies m under a display name not in taken, and adds the name.
+func cloneModel(m config.CustomModel, taken map[string]bool) config.CustomModel {
+	name := m.DisplayName
+	if name == "" {
+		name = m.Model
+	}
+	m.DisplayName = strings.TrimSpace(name + " (copy)")
+	for n := 2; taken[nameKey(m.DisplayName)]; n++ {
+		m.DisplayName = strings.TrimSpace(fmt.Sprintf("%s (copy %d)", name, n))
+	}
+	taken[nameKey(m.DisplayName)] = true
+	return m
+}
+
+// nameKey is how display names are compared for uniqueness.
+func nameKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
 }
 
 func (l *List) DeleteSelected() int {
@@ -240,13 +471,13 @@ func (l *List) DeleteSelected() int {
 	if l.Cursor >= len(l.Items) {
 		l.Cursor = max(0, len(l.Items)-1)
 	}
-	l.updateOffset()
+	l.refresh()
 
 	return len(indices)
 }
 
 func (l *List) DeleteCurrent() bool {
-	if len(l.Items) == 0 || l.Cursor < 0 || l.Cursor >= len(l.Items) {
+	if l.CurrentModel() == nil {
 		return false
 	}
 
@@ -254,27 +485,33 @@ func (l *List) DeleteCurrent() bool {
 	if l.Cursor >= len(l.Items) {
 		l.Cursor = max(0, len(l.Items)-1)
 	}
-	l.updateOffset()
+	l.refresh()
 	return true
 }
 
 func (l *List) MoveItemUp() bool {
-	if l.Cursor <= 0 || l.Cursor >= len(l.Items) {
-		return false
-	}
-	l.Items[l.Cursor], l.Items[l.Cursor-1] = l.Items[l.Cursor-1], l.Items[l.Cursor]
-	l.Cursor--
-	l.updateOffset()
-	return true
+	return l.moveItem(-1)
 }
 
 func (l *List) MoveItemDown() bool {
-	if l.Cursor < 0 || l.Cursor >= len(l.Items)-1 {
+	return l.moveItem(1)
+}
+
+// moveItem swaps the cursor item with the item shown dir rows away. Items a
+// filter hides keep their places.
+func (l *List) moveItem(dir int) bool {
+	row := l.cursorRow()
+	if l.header != "" || row < 0 {
 		return false
 	}
-	l.Items[l.Cursor], l.Items[l.Cursor+1] = l.Items[l.Cursor+1], l.Items[l.Cursor]
-	l.Cursor++
-	l.updateOffset()
+	other := row + dir
+	if other < 0 || other >= len(l.rows) || l.rows[other].header() {
+		return false
+	}
+	j := l.rows[other].item
+	l.Items[l.Cursor], l.Items[j] = l.Items[j], l.Items[l.Cursor]
+	l.Cursor = j
+	l.refresh()
 	return true
 }
 
@@ -308,7 +545,7 @@ func (l *List) View(focused bool, dirty bool) string {
 	}
 
 	allSelectText := "[A] Select All"
-	if l.AllSelected() && len(l.Items) > 0 {
+	if l.AllSelected() {
 		allSelectText = "[A] Deselect All"
 	}
 	var lines []string
@@ -317,13 +554,27 @@ func (l *List) View(focused bool, dirty bool) string {
 		lines = append(lines, titleStyle.Render(padOrTruncate("[N] New  [D] Delete", headerWidth)))
 	}
 	if headerLines >= 2 {
-		lines = append(lines, lipgloss.NewStyle().Foreground(secondaryColor).Render(padOrTruncate(allSelectText, headerWidth)))
+		switch {
+		case l.searching:
+			l.search.Width = max(1, headerWidth-lipgloss.Width(l.search.Prompt)-1)
+			lines = append(lines, padOrTruncate(l.search.View(), headerWidth))
+		case l.filter != "":
+			lines = append(lines, matchStyle.Render(padOrTruncate("/"+l.filter+"  (n/N, esc)", headerWidth)))
+		default:
+			lines = append(lines, lipgloss.NewStyle().Foreground(secondaryColor).Render(padOrTruncate(allSelectText, headerWidth)))
+		}
 	}
 	if headerLines >= 3 {
 		lines = append(lines, lipgloss.NewStyle().Foreground(secondaryColor).Render(strings.Repeat("─", headerWidth)))
 	}
 
 	titleText := "YOUR MODELS"
+	if l.filter != "" {
+		titleText += fmt.Sprintf(" (%d/%d)", len(l.matches), len(l.Items))
+	}
+	if l.mode != ViewFileOrder {
+		titleText += " · " + l.mode.String()
+	}
 	if dirty {
 		titleText += " *"
 	}
@@ -346,27 +597,42 @@ func (l *List) View(focused bool, dirty bool) string {
 		for i := 0; i < visibleHeight && i < len(emptyLines); i++ {
 			lines = append(lines, emptyStyle.Render(padOrTruncate(emptyLines[i], headerWidth)))
 		}
+	} else if len(l.rows) == 0 && l.filter != "" {
+		emptyStyle := lipgloss.NewStyle().Foreground(secondaryColor).Italic(true)
+		lines = append(lines, emptyStyle.Render(padOrTruncate("  No matches for \""+l.filter+"\"", headerWidth)))
 	} else {
 		end := l.offset + visibleHeight
-		if end > len(l.Items) {
-			end = len(l.Items)
+		if end > len(l.rows) {
+			end = len(l.rows)
 		}
 
 		// Check if we need edge indicators
 		hasItemsAbove := l.offset > 0
-		hasItemsBelow := end < len(l.Items)
+		hasItemsBelow := end < len(l.rows)
 
 		// Generate scrollbar if list is scrollable
 		scrollbar := l.renderScrollbar(visibleHeight)
 
 		// Build list items with scrollbar
-		contentWidth := headerWidth - scrollbarWidth
-		if contentWidth < 10 {
-			contentWidth = 10
-		}
+		contentWidth := l.contentWidth()
 
 		var listLines []string
-		for i := l.offset; i < end; i++ {
+		for _, r := range l.rows[l.offset:end] {
+			if r.header() {
+				arrow := "▾"
+				if l.collapsed[r.group] {
+					arrow = "▸"
+				}
+				rawLine := padOrTruncate(fmt.Sprintf("%s %s (%d)", arrow, r.group, r.size), contentWidth)
+				if r.group == l.header && focused {
+					listLines = append(listLines, selectedItemStyle.Render(rawLine))
+				} else {
+					listLines = append(listLines, groupHeaderStyle.Render(rawLine))
+				}
+				continue
+			}
+
+			i := r.item
 			item := l.Items[i]
 			checkbox := checkboxUnchecked
 			if item.Selected {
@@ -378,30 +644,24 @@ func (l *List) View(focused bool, dirty bool) string {
 				badge = lipgloss.NewStyle().Foreground(secondaryColor).Render("[?]")
 			}
 
-			name := item.Model.DisplayName
-			if name == "" {
-				name = item.Model.Model
-			}
-			if name == "" {
-				name = "(unnamed)"
-			}
-
 			// Truncate to keep each rendered line within the panel width.
 			digits := len(strconv.Itoa(i + 1))
 			maxNameLen := contentWidth - (digits + 10)
 			if maxNameLen < 5 {
 				maxNameLen = 5
 			}
-			if len(name) > maxNameLen {
-				name = name[:maxNameLen-3] + "..."
-			}
+			name := highlightLabel(itemLabel(item.Model), l.matches[i], maxNameLen)
 
 			rawLine := fmt.Sprintf("%s %s %d. %s", checkbox, badge, i+1, name)
 			rawLine = padOrTruncate(rawLine, contentWidth)
 
-			if i == l.Cursor && focused {
+			if l.mode.Grouped() {
+				rawLine = padOrTruncate("  "+rawLine, contentWidth)
+			}
+
+			if i == l.Cursor && l.header == "" && focused {
 				listLines = append(listLines, selectedItemStyle.Render(rawLine))
-			} else if i == l.Cursor {
+			} else if i == l.Cursor && l.header == "" {
 				listLines = append(listLines, itemStyle.Bold(true).Render(rawLine))
 			} else {
 				listLines = append(listLines, itemStyle.Render(rawLine))
@@ -431,7 +691,7 @@ func (l *List) View(focused bool, dirty bool) string {
 
 // renderScrollbar generates a vertical scrollbar as a slice of characters
 func (l *List) renderScrollbar(visibleHeight int) []string {
-	totalItems := len(l.Items)
+	totalItems := len(l.rows)
 	if totalItems <= visibleHeight {
 		// No scrollbar needed - return empty strings
 		result := make([]string, visibleHeight)
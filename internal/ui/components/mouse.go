@@ -0,0 +1,167 @@
+package components
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// wheelScrollLines is how many rows one wheel notch scrolls the list.
+const wheelScrollLines = 3
+
+// HandleMouse handles a mouse event at x, y relative to the top-left corner
+// of the list content. The wheel scrolls without moving the cursor, the
+// scrollbar thumb can be dragged, a click on a row moves the cursor there, a
+// click on a checkbox toggles it and a click on a group header collapses or
+// expands the group. It reports whether the cursor moved.
+func (l *List) HandleMouse(msg tea.MouseMsg, x, y int) bool {
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		l.Scroll(-wheelScrollLines)
+		return false
+	case tea.MouseButtonWheelDown:
+		l.Scroll(wheelScrollLines)
+		return false
+	}
+
+	switch msg.Action {
+	case tea.MouseActionRelease:
+		l.dragging = false
+		return false
+	case tea.MouseActionMotion:
+		if l.dragging {
+			l.dragThumb(y - l.headerLinesToShow())
+		}
+		return false
+	}
+	if msg.Button != tea.MouseButtonLeft {
+		return false
+	}
+
+	if l.ScrollbarAt(x, y) {
+		visible := y - l.headerLinesToShow()
+		pos, size := l.thumb(l.getVisibleHeight())
+		l.dragAnchor = size / 2
+		if visible >= pos && visible < pos+size {
+			l.dragAnchor = visible - pos
+		}
+		l.dragging = true
+		l.dragThumb(visible)
+		return false
+	}
+
+	row := l.RowAt(y)
+	if row < 0 {
+		return false
+	}
+	before, header := l.Cursor, l.header
+	l.setRow(row)
+	r := l.rows[row]
+	switch {
+	case r.header():
+		l.ToggleGroup()
+	case l.checkboxAt(x):
+		l.ToggleSelected()
+	}
+	return l.Cursor != before || l.header != header
+}
+
+// Dragging reports whether the scrollbar thumb is being dragged, in which
+// case mouse motion should reach the list wherever the pointer is.
+func (l *List) Dragging() bool {
+	return l.dragging
+}
+
+// RowAt returns the display row drawn at line y of the list, or -1.
+func (l *List) RowAt(y int) int {
+	line := y - l.headerLinesToShow()
+	if line < 0 || line >= l.getVisibleHeight() {
+		return -1
+	}
+	if row := l.offset + line; row < len(l.rows) {
+		return row
+	}
+	return -1
+}
+
+// ScrollbarAt reports whether x, y is on the scrollbar. There is only a
+// scrollbar when the rows do not fit.
+func (l *List) ScrollbarAt(x, y int) bool {
+	visible := l.getVisibleHeight()
+	line := y - l.headerLinesToShow()
+	return len(l.rows) > visible && x >= l.contentWidth() && line >= 0 && line < visible
+}
+
+// Scroll moves the shown rows by delta without moving the cursor.
+func (l *List) Scroll(delta int) {
+	l.offset = max(0, min(l.offset+delta, len(l.rows)-l.getVisibleHeight()))
+}
+
+// dragThumb scrolls so the grabbed part of the thumb is at visible line y.
+func (l *List) dragThumb(y int) {
+	visible := l.getVisibleHeight()
+	_, size := l.thumb(visible)
+	track := visible - size
+	maxOffset := len(l.rows) - visible
+	if track <= 0 || maxOffset <= 0 {
+		return
+	}
+	pos := max(0, min(y-l.dragAnchor, track))
+	l.offset = (pos*maxOffset + track/2) / track
+}
+
+// thumb returns the position and size of the scrollbar thumb, as drawn by
+// renderScrollbar.
+func (l *List) thumb(visibleHeight int) (pos, size int) {
+	total := len(l.rows)
+	if total <= visibleHeight {
+		return 0, visibleHeight
+	}
+	size = min(visibleHeight, max(1, visibleHeight*visibleHeight/total))
+	if maxOffset := total - visibleHeight; maxOffset > 0 {
+		pos = l.offset * (visibleHeight - size) / maxOffset
+	}
+	return pos, size
+}
+
+// contentWidth is the width of a row, left of the scrollbar.
+func (l *List) contentWidth() int {
+	return max(10, l.Width-scrollbarWidth)
+}
+
+// checkboxAt reports whether column x is on the checkbox of an item row.
+func (l *List) checkboxAt(x int) bool {
+	start := 0
+	if l.mode.Grouped() {
+		start = 2
+	}
+	return x >= start && x < start+3
+}
+
+// fieldSpan is where the last View drew a field, in lines of the form.
+type fieldSpan struct {
+	field, top, bottom int
+}
+
+// FieldAt returns the field drawn at line y of the form, or -1.
+func (f *Form) FieldAt(y int) int {
+	for _, s := range f.spans {
+		if y >= s.top && y < s.bottom {
+			return s.field
+		}
+	}
+	return -1
+}
+
+// ProviderArrowAt returns -1 when x, y is on the "<" of the provider
+// selector, 1 when it is on the ">", and 0 otherwise.
+func (f *Form) ProviderArrowAt(x, y int) int {
+	if f.providerRow < 0 || y != f.providerRow {
+		return 0
+	}
+	switch x {
+	case f.providerArrows[0]:
+		return -1
+	case f.providerArrows[1]:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,168 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Picker is a modal list of strings narrowed by a filter typed by the user.
+type Picker struct {
+	Active  bool
+	Title   string
+	Width   int
+	Height  int
+	items   []string
+	matches []string
+	filter  textinput.Model
+	cursor  int
+	offset  int
+}
+
+func NewPicker() *Picker {
+	t := textinput.New()
+	t.Placeholder = "type to filter"
+	t.CharLimit = 128
+	return &Picker{
+		Width:  50,
+		Height: 16,
+		filter: t,
+	}
+}
+
+func (p *Picker) Show(title string, items []string) {
+	p.Active = true
+	p.Title = title
+	p.items = items
+	p.cursor = 0
+	p.offset = 0
+	p.filter.SetValue("")
+	p.filter.Focus()
+	p.applyFilter()
+}
+
+func (p *Picker) Hide() {
+	p.Active = false
+	p.filter.Blur()
+}
+
+// Selected returns the highlighted item, or "" when nothing matches.
+func (p *Picker) Selected() string {
+	if p.cursor >= 0 && p.cursor < len(p.matches) {
+		return p.matches[p.cursor]
+	}
+	return ""
+}
+
+func (p *Picker) MoveUp() {
+	if p.cursor > 0 {
+		p.cursor--
+	}
+	p.updateOffset()
+}
+
+func (p *Picker) MoveDown() {
+	if p.cursor < len(p.matches)-1 {
+		p.cursor++
+	}
+	p.updateOffset()
+}
+
+// FilterInput returns the filter input so the caller can forward key messages.
+func (p *Picker) FilterInput() *textinput.Model {
+	return &p.filter
+}
+
+// UpdateFilter stores the updated filter input and re-filters the items.
+func (p *Picker) UpdateFilter(input textinput.Model) {
+	changed := input.Value() != p.filter.Value()
+	p.filter = input
+	if changed {
+		p.cursor = 0
+		p.offset = 0
+		p.applyFilter()
+	}
+}
+
+// applyFilter keeps items containing every space-separated filter word.
+func (p *Picker) applyFilter() {
+	words := strings.Fields(strings.ToLower(p.filter.Value()))
+	p.matches = p.matches[:0]
+	for _, item := range p.items {
+		lower := strings.ToLower(item)
+		keep := true
+		for _, w := range words {
+			if !strings.Contains(lower, w) {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			p.matches = append(p.matches, item)
+		}
+	}
+}
+
+func (p *Picker) visibleRows() int {
+	// Title, filter, blank line, footer and the modal's border and padding.
+	return max(1, p.Height-8)
+}
+
+func (p *Picker) updateOffset() {
+	rows := p.visibleRows()
+	if p.cursor < p.offset {
+		p.offset = p.cursor
+	}
+	if p.cursor >= p.offset+rows {
+		p.offset = p.cursor - rows + 1
+	}
+}
+
+func (p *Picker) View() string {
+	if !p.Active {
+		return ""
+	}
+
+	primaryColor := lipgloss.Color("39")
+	secondaryColor := lipgloss.Color("240")
+
+	innerWidth := max(10, p.Width-4)
+	p.filter.Width = max(1, innerWidth-lipgloss.Width(p.filter.Prompt)-1)
+
+	modalStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(primaryColor).
+		Padding(0, 1).
+		Width(p.Width)
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(primaryColor)
+	itemStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("252"))
+	selectedStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("0")).Background(primaryColor)
+	hintStyle := lipgloss.NewStyle().Foreground(secondaryColor)
+
+	lines := []string{
+		titleStyle.Render(padOrTruncate(p.Title, innerWidth)),
+		p.filter.View(),
+		"",
+	}
+
+	rows := p.visibleRows()
+	if len(p.matches) == 0 {
+		lines = append(lines, hintStyle.Italic(true).Render(padOrTruncate("  No matches", innerWidth)))
+	}
+	end := min(len(p.matches), p.offset+rows)
+	for i := p.offset; i < end; i++ {
+		text := padOrTruncate(" "+p.matches[i], innerWidth)
+		if i == p.cursor {
+			lines = append(lines, selectedStyle.Render(text))
+		} else {
+			lines = append(lines, itemStyle.Render(text))
+		}
+	}
+
+	lines = append(lines, "", hintStyle.Render(padOrTruncate(
+		fmt.Sprintf("%d/%d | ↑↓: move | enter: pick | esc: cancel", len(p.matches), len(p.items)), innerWidth)))
+
+	return modalStyle.Render(strings.Join(lines, "\n"))
+}
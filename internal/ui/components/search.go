@@ -0,0 +1,178 @@
+package components
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/diogo/droid-config/internal/config"
+)
+
+var matchStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+
+// itemLabel is the text shown for a model in the list.
+func itemLabel(m config.CustomModel) string {
+	switch {
+	case m.DisplayName != "":
+		return m.DisplayName
+	case m.Model != "":
+		return m.Model
+	}
+	return "(unnamed)"
+}
+
+// matchModel fuzzy-matches pattern against the fields of m. The returned
+// positions index runes of the list label and are nil when only another
+// field (model ID, base URL, provider) matched.
+func matchModel(pattern string, m config.CustomModel) ([]int, bool) {
+	if positions, ok := fuzzyMatch(pattern, itemLabel(m)); ok {
+		return positions, true
+	}
+	for _, field := range []string{m.Model, m.BaseURL, m.Provider} {
+		if _, ok := fuzzyMatch(pattern, field); ok {
+			return nil, true
+		}
+	}
+	return nil, false
+}
+
+// fuzzyMatch reports whether the characters of pattern appear in text in
+// order, ignoring case and spaces in the pattern. A contiguous match is
+// preferred so that highlighting reads naturally.
+func fuzzyMatch(pattern, text string) ([]int, bool) {
+	pat := []rune(strings.ToLower(strings.ReplaceAll(pattern, " ", "")))
+	if len(pat) == 0 {
+		return nil, true
+	}
+	runes := []rune(text)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	if start := strings.Index(string(lower), string(pat)); start >= 0 {
+		first := len([]rune(string(lower)[:start]))
+		positions := make([]int, len(pat))
+		for i := range pat {
+			positions[i] = first + i
+		}
+		return positions, true
+	}
+
+	positions := make([]int, 0, len(pat))
+	p := 0
+	for i, r := range lower {
+		if p < len(pat) && r == pat[p] {
+			positions = append(positions, i)
+			p++
+		}
+	}
+	if p < len(pat) {
+		return nil, false
+	}
+	return positions, true
+}
+
+// highlightLabel truncates label to maxLen runes and emphasizes the runes at
+// the matched positions.
+func highlightLabel(label string, positions []int, maxLen int) string {
+	runes := []rune(label)
+	truncated := false
+	if len(runes) > maxLen {
+		runes = runes[:max(0, maxLen-3)]
+		truncated = true
+	}
+
+	var b strings.Builder
+	p := 0
+	for i, r := range runes {
+		for p < len(positions) && positions[p] < i {
+			p++
+		}
+		if p < len(positions) && positions[p] == i {
+			b.WriteString(matchStyle.Render(string(r)))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	if truncated {
+		b.WriteString("...")
+	}
+	return b.String()
+}
+
+// StartSearch enters search mode, editing the current filter.
+func (l *List) StartSearch() {
+	l.searching = true
+	l.search.SetValue(l.filter)
+	l.search.CursorEnd()
+	l.search.Focus()
+}
+
+// Searching reports whether the search input is being edited.
+func (l *List) Searching() bool {
+	return l.searching
+}
+
+// Filtering reports whether a filter currently narrows the list.
+func (l *List) Filtering() bool {
+	return l.filter != ""
+}
+
+// SearchInput returns the search input so the caller can forward key messages.
+func (l *List) SearchInput() *textinput.Model {
+	return &l.search
+}
+
+// UpdateSearch stores the updated search input and re-filters the items.
+func (l *List) UpdateSearch(input textinput.Model) {
+	l.search = input
+	if input.Value() != l.filter {
+		l.filter = input.Value()
+		l.refresh()
+	}
+}
+
+// EndSearch leaves search mode, keeping the filter applied.
+func (l *List) EndSearch() {
+	l.searching = false
+	l.search.Blur()
+}
+
+// ClearFilter leaves search mode and shows every item again.
+func (l *List) ClearFilter() {
+	l.EndSearch()
+	l.search.SetValue("")
+	if l.filter != "" {
+		l.filter = ""
+		l.refresh()
+	}
+}
+
+// NextMatch moves the cursor to the next shown item, wrapping around and
+// skipping group headers.
+func (l *List) NextMatch() {
+	l.stepItem(1)
+}
+
+// PrevMatch moves the cursor to the previous shown item, wrapping around and
+// skipping group headers.
+func (l *List) PrevMatch() {
+	l.stepItem(-1)
+}
+
+func (l *List) stepItem(dir int) {
+	n := len(l.rows)
+	row := l.cursorRow()
+	if row < 0 {
+		row = 0
+	}
+	for step := 1; step <= n; step++ {
+		next := ((row+dir*step)%n + n) % n
+		if !l.rows[next].header() {
+			l.setRow(next)
+			return
+		}
+	}
+}
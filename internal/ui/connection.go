@@ -0,0 +1,61 @@
+package ui
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diogo/droid-config/internal/config"
+	"github.com/diogo/droid-config/internal/endpoint"
+	"github.com/diogo/droid-config/internal/ui/components"
+)
+
+const connectionTestTimeout = 20 * time.Second
+
+type connectionTestMsg struct {
+	name   string
+	result endpoint.Result
+}
+
+// testConnectionCmd runs endpoint.Test off the UI goroutine.
+func testConnectionCmd(m config.CustomModel) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), connectionTestTimeout)
+		defer cancel()
+		client := &http.Client{Timeout: connectionTestTimeout}
+		return connectionTestMsg{name: m.DisplayName, result: endpoint.Test(ctx, client, m)}
+	}
+}
+
+// testConnection tests the values currently in the form, saved or not.
+func (m Model) testConnection() (tea.Model, tea.Cmd) {
+	if m.testing {
+		m.status.SetInfo("Connection test already running...")
+		return m, statusClearCmd()
+	}
+
+	model := m.form.GetModel()
+	if model.BaseURL == "" {
+		m.status.SetError("Set a Base URL before testing the connection")
+		return m, statusClearCmd()
+	}
+
+	m.testing = true
+	m.status.Set("Testing "+model.BaseURL+"...", components.StatusInfo, connectionTestTimeout)
+	return m, testConnectionCmd(model)
+}
+
+func (m Model) handleConnectionResult(msg connectionTestMsg) (tea.Model, tea.Cmd) {
+	m.testing = false
+	summary := msg.result.Summary()
+	if msg.name != "" {
+		summary = msg.name + ": " + summary
+	}
+	if msg.result.OK() {
+		m.status.SetSuccess(summary)
+	} else {
+		m.status.Set(summary, components.StatusError, 10*time.Second)
+	}
+	return m, statusClearCmd()
+}
@@ -0,0 +1,160 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/diogo/droid-config/internal/config"
+	"github.com/diogo/droid-config/internal/interop"
+)
+
+// exportView asks where to write the selected models and how to treat keys.
+type exportView struct {
+	input   textinput.Model
+	models  []config.CustomModel
+	secrets int // index into interop.SecretModes
+}
+
+// openExport exports the selected models, or the current one when nothing is selected.
+func (m Model) openExport() (tea.Model, tea.Cmd) {
+	var models []config.CustomModel
+	for _, idx := range m.list.GetSelectedIndices() {
+		models = append(models, m.list.Items[idx].Model)
+	}
+	if len(models) == 0 {
+		if currentModel := m.list.CurrentModel(); currentModel != nil {
+			models = append(models, *currentModel)
+		}
+	}
+	if len(models) == 0 {
+		m.status.SetWarning("No models to export")
+		return m, statusClearCmd()
+	}
+
+	t := textinput.New()
+	t.CharLimit = 1024
+	t.SetValue("droid-models.json")
+	t.CursorEnd()
+	t.Focus()
+	m.exporter = &exportView{input: t, models: models}
+	return m, textinput.Blink
+}
+
+func (m Model) handleExportKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	v := m.exporter
+
+	switch {
+	case key.Matches(msg, Keys.Quit):
+		return m.quit(msg)
+
+	case key.Matches(msg, Keys.Escape):
+		m.exporter = nil
+		return m, nil
+
+	case key.Matches(msg, Keys.Tab):
+		v.secrets = (v.secrets + 1) % len(interop.SecretModes)
+		return m, nil
+
+	case key.Matches(msg, Keys.ShiftTab):
+		v.secrets = (v.secrets - 1 + len(interop.SecretModes)) % len(interop.SecretModes)
+		return m, nil
+
+	case key.Matches(msg, Keys.Enter):
+		return m.writeExport(false)
+	}
+
+	newInput, cmd := v.input.Update(msg)
+	v.input = newInput
+	return m, cmd
+}
+
+// overwriteExportMsg is sent when replacing an existing file with the
+// export was confirmed.
+type overwriteExportMsg struct{}
+
+// writeExport writes the bundle, asking first when a file is already there
+// unless overwrite is set.
+func (m Model) writeExport(overwrite bool) (tea.Model, tea.Cmd) {
+	v := m.exporter
+	if v == nil {
+		return m, nil
+	}
+	path := strings.TrimSpace(v.input.Value())
+	if path == "" {
+		return m, nil
+	}
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[1:])
+		}
+	}
+
+	if _, err := os.Stat(path); err == nil && !overwrite {
+		m.askConfirm(config.DisplayPath(path)+" already exists. Overwrite it?", overwriteExportMsg{})
+		return m, nil
+	}
+
+	data, err := interop.Export(v.models, interop.ExportFormatFor(path), interop.SecretModes[v.secrets])
+	if err == nil {
+		err = os.WriteFile(path, data, 0600)
+	}
+	if err != nil {
+		m.status.SetError("Export failed: " + err.Error())
+		return m, statusClearCmd()
+	}
+
+	m.exporter = nil
+	m.status.SetSuccess(fmt.Sprintf("Exported %d model(s) to %s", len(v.models), path))
+	return m, statusClearCmd()
+}
+
+func (m Model) renderExport() string {
+	v := m.exporter
+	width := max(1, m.width-4)
+	v.input.Width = max(1, width-lipgloss.Width(v.input.Prompt)-1)
+
+	secretHelp := map[interop.SecretMode]string{
+		interop.SecretsEnv:    "replace plain keys with env: placeholders",
+		interop.SecretsRedact: "remove plain keys",
+		interop.SecretsKeep:   "keep plain keys (do not commit the file!)",
+	}
+	var modes []string
+	for i, mode := range interop.SecretModes {
+		label := string(mode)
+		if i == v.secrets {
+			label = SelectedStyle.Render(" " + label + " ")
+		} else {
+			label = BlurredStyle.Render(" " + label + " ")
+		}
+		modes = append(modes, label)
+	}
+	current := interop.SecretModes[v.secrets]
+
+	lines := []string{
+		TitleBackgroundStyle.Render(padOrTruncate(fmt.Sprintf("EXPORT %d MODEL(S)", len(v.models)), max(0, width-2))),
+		"",
+		padOrTruncate("Write to (.json or .yaml):", width),
+		v.input.View(),
+		"",
+		padOrTruncate("API keys: "+strings.Join(modes, " "), width),
+		HintStyle.Render(padOrTruncate("  "+secretHelp[current]+"; key references are always kept", width)),
+		"",
+	}
+	for _, model := range v.models {
+		lines = append(lines, padOrTruncate("  "+ProviderBadges[model.Provider]+" "+model.DisplayName, width))
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(primaryColor).
+		Width(max(0, m.width-2)).
+		Height(max(0, m.contentHeight-2)).
+		Padding(0, 1).
+		Render(strings.Join(lines, "\n"))
+}
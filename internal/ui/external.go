@@ -0,0 +1,192 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diogo/droid-config/internal/config"
+	"github.com/diogo/droid-config/internal/ui/components"
+)
+
+// fileWatchInterval is how often the config file is checked for changes
+// made by other programs.
+const fileWatchInterval = 2 * time.Second
+
+// fileCheckedMsg reports the state of the config file on disk.
+type fileCheckedMsg struct {
+	state config.FileState
+	err   error
+}
+
+func watchFileCmd(path string) tea.Cmd {
+	return tea.Tick(fileWatchInterval, func(time.Time) tea.Msg {
+		state, err := config.StatFile(path)
+		return fileCheckedMsg{state: state, err: err}
+	})
+}
+
+// externalChange is the prompt shown when the file changed on disk since it
+// was last loaded or saved.
+type externalChange struct {
+	state  config.FileState
+	theirs *config.ConfigData // nil when the file can no longer be parsed
+	err    error
+	saving bool // a save is waiting for the decision
+}
+
+// markSynced records that the file on disk matches the loaded models.
+func (m *Model) markSynced() {
+	if state, err := config.StatFile(m.configPath); err == nil {
+		m.diskState = state
+	}
+	m.ignoredState = m.diskState
+	m.baseModels = m.list.GetModels()
+	m.baseExtra = m.config.Extra()
+}
+
+func (m Model) handleFileChecked(msg fileCheckedMsg) (tea.Model, tea.Cmd) {
+	next := watchFileCmd(m.configPath)
+	if msg.err != nil || m.loadErr != nil || m.external != nil || m.dialog.Active {
+		return m, next
+	}
+	if !msg.state.Changed(m.diskState) || !msg.state.Changed(m.ignoredState) {
+		return m, next
+	}
+
+	m.openExternalChange(msg.state, false)
+	return m, next
+}
+
+// externalAction is the answer to the external change prompt.
+type externalAction int
+
+const (
+	externalLater externalAction = iota
+	externalReload
+	externalOverwrite
+	externalMerge
+)
+
+// externalChoiceMsg is sent when a button of the external change prompt was
+// chosen.
+type externalChoiceMsg struct {
+	action externalAction
+}
+
+// openExternalChange reads the new file content and shows the prompt.
+func (m *Model) openExternalChange(state config.FileState, saving bool) {
+	cfg, err := config.Load(m.configPath)
+	m.external = &externalChange{state: state, theirs: cfg, err: err, saving: saving}
+
+	choice := func(label string, b key.Binding, action externalAction) components.DialogButton {
+		return components.DialogButton{Label: buttonLabel(label, b), Key: b, Msg: components.Reply(externalChoiceMsg{action: action})}
+	}
+	later := components.DialogButton{Label: buttonLabel("Later", Keys.Escape), Cancel: true, Msg: components.Reply(externalChoiceMsg{action: externalLater})}
+
+	lines := []string{config.DisplayPath(m.configPath) + " changed on disk.", ""}
+	var buttons []components.DialogButton
+	if cfg == nil {
+		lines = append(lines, "The new content can't be parsed:", ErrorStyle.Render(err.Error()))
+		buttons = []components.DialogButton{choice("Overwrite", Keys.Overwrite, externalOverwrite), later}
+	} else {
+		lines = append(lines,
+			fmt.Sprintf("Disk: %d model(s)   Here: %d model(s)", len(cfg.CustomModels), len(m.list.Items)),
+			"",
+			"Reload drops your changes, Overwrite keeps only yours,",
+			"Merge keeps both (your version wins conflicts).",
+		)
+		buttons = []components.DialogButton{
+			choice("Reload", Keys.Reload, externalReload),
+			choice("Overwrite", Keys.Overwrite, externalOverwrite),
+			choice("Merge", Keys.Merge, externalMerge),
+			later,
+		}
+	}
+	if saving {
+		lines = append(lines, "", HintStyle.Render("Your last change has not been saved yet."))
+	}
+	if cfg != nil && m.form.Dirty() {
+		lines = append(lines, "", HintStyle.Render("The form has unsaved edits: Reload discards them, Merge saves them."))
+	}
+	m.dialog.Show("FILE CHANGED", strings.Join(lines, "\n"), buttons...)
+}
+
+func (m Model) resolveExternal(action externalAction) (tea.Model, tea.Cmd) {
+	e := m.external
+	if e == nil {
+		return m, nil
+	}
+	switch action {
+	case externalReload:
+		if e.theirs == nil {
+			return m, nil
+		}
+		m.history.push(m.snapshot())
+		cursor := m.list.Cursor
+		m.setConfig(e.theirs)
+		m.list.SetCursor(cursor)
+		m.loadCurrentModel()
+		m.external = nil
+		m.dirty = false
+		m.status.SetInfo("Reloaded changes from disk")
+		return m, statusClearCmd()
+
+	case externalOverwrite:
+		m.external = nil
+		m.diskState = e.state
+		if e.theirs == nil {
+			m.forceSave = true
+		}
+		m.status.SetWarning("Overwrote the changes on disk")
+		return m.saveConfig()
+
+	case externalMerge:
+		if e.theirs == nil {
+			return m, nil
+		}
+		// Unsaved form edits are part of our side, so they survive the merge.
+		ours := m.list.GetModels()
+		if m.form.Dirty() && m.list.CurrentModel() != nil {
+			valid, errMsg, _ := m.form.Validate(ours, m.list.Cursor)
+			if !valid {
+				m.external = nil
+				m.ignoredState = e.state
+				m.dirty = m.dirty || e.saving
+				m.status.SetError("Not merged - fix the form first: " + errMsg)
+				return m, statusClearCmd()
+			}
+			ours[m.list.Cursor] = m.form.GetModel()
+		}
+		merged, conflicts := config.MergeModels(m.baseModels, ours, e.theirs.CustomModels)
+		extra := config.MergeExtra(m.baseExtra, m.config.Extra(), e.theirs.Extra())
+		m.history.push(m.snapshot())
+		cursor := m.list.Cursor
+		m.config = e.theirs
+		m.config.SetExtra(extra)
+		m.reloadSettings()
+		m.list.SetItems(merged)
+		m.list.SetCursor(cursor)
+		m.loadCurrentModel()
+		m.external = nil
+		m.diskState = e.state
+		if len(conflicts) > 0 {
+			m.status.SetWarning(fmt.Sprintf("Merged with %d conflict(s); kept your version: %s", len(conflicts), conflicts[0]))
+		} else {
+			m.status.SetSuccess("Merged changes from disk")
+		}
+		return m.saveConfig()
+	}
+
+	m.external = nil
+	m.ignoredState = e.state
+	if e.saving {
+		m.dirty = true
+		m.status.SetWarning("Not saved - the file changed on disk")
+	} else {
+		m.status.SetWarning("Ignoring the change on disk until the next save")
+	}
+	return m, statusClearCmd()
+}
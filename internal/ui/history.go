@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"encoding/json"
+
+	"github.com/diogo/droid-config/internal/config"
+)
+
+const historyLimit = 100
+
+// snapshot is the state of the model list and the other settings at one
+// point in time.
+type snapshot struct {
+	models []config.CustomModel
+	cursor int
+	extra  map[string]json.RawMessage
+}
+
+// history is an undo/redo stack of model list snapshots.
+type history struct {
+	undo []snapshot
+	redo []snapshot
+}
+
+func newHistory() *history {
+	return &history{}
+}
+
+// push records the state before a mutation and invalidates the redo stack.
+func (h *history) push(s snapshot) {
+	h.undo = append(h.undo, s)
+	if len(h.undo) > historyLimit {
+		h.undo = h.undo[len(h.undo)-historyLimit:]
+	}
+	h.redo = nil
+}
+
+// Undo returns the previous state, saving current for redo.
+func (h *history) Undo(current snapshot) (snapshot, bool) {
+	if len(h.undo) == 0 {
+		return snapshot{}, false
+	}
+	s := h.undo[len(h.undo)-1]
+	h.undo = h.undo[:len(h.undo)-1]
+	h.redo = append(h.redo, current)
+	return s, true
+}
+
+// Redo returns the state undone last, saving current for undo.
+func (h *history) Redo(current snapshot) (snapshot, bool) {
+	if len(h.redo) == 0 {
+		return snapshot{}, false
+	}
+	s := h.redo[len(h.redo)-1]
+	h.redo = h.redo[:len(h.redo)-1]
+	h.undo = append(h.undo, current)
+	return s, true
+}
@@ -0,0 +1,260 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/diogo/droid-config/internal/config"
+	"github.com/diogo/droid-config/internal/interop"
+	"github.com/diogo/droid-config/internal/ui/components"
+)
+
+// importView asks for a file from another tool, then previews the models
+// found in it so the user can pick which to add.
+type importView struct {
+	input      textinput.Model
+	source     string
+	format     interop.Format
+	candidates []interop.Candidate
+	selected   []bool
+	cursor     int
+	offset     int
+}
+
+func (v *importView) previewing() bool {
+	return v.candidates != nil
+}
+
+func (m Model) openImport() (tea.Model, tea.Cmd) {
+	t := textinput.New()
+	t.Placeholder = "~/.env, litellm config.yaml, ~/.continue/config.json, .aider.conf.yml"
+	t.CharLimit = 1024
+	t.Focus()
+	m.importer = &importView{input: t}
+	return m, textinput.Blink
+}
+
+func (m Model) handleImportKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	v := m.importer
+
+	if key.Matches(msg, Keys.Quit) {
+		return m.quit(msg)
+	}
+
+	if !v.previewing() {
+		switch {
+		case key.Matches(msg, Keys.Escape):
+			m.importer = nil
+			return m, nil
+		case key.Matches(msg, Keys.Enter):
+			return m.loadImportFile()
+		}
+		newInput, cmd := v.input.Update(msg)
+		v.input = newInput
+		return m, cmd
+	}
+
+	visible := m.importVisibleLines()
+	switch {
+	case key.Matches(msg, Keys.Escape):
+		v.candidates = nil
+		v.input.Focus()
+		return m, nil
+	case key.Matches(msg, Keys.Up):
+		if v.cursor > 0 {
+			v.cursor--
+		}
+	case key.Matches(msg, Keys.Down):
+		if v.cursor < len(v.candidates)-1 {
+			v.cursor++
+		}
+	case key.Matches(msg, Keys.Space):
+		if v.cursor < len(v.selected) {
+			v.selected[v.cursor] = !v.selected[v.cursor]
+		}
+	case key.Matches(msg, Keys.SelectAll):
+		all := true
+		for _, s := range v.selected {
+			all = all && s
+		}
+		for i := range v.selected {
+			v.selected[i] = !all
+		}
+	case key.Matches(msg, Keys.Enter):
+		return m.confirmImport()
+	}
+
+	if v.cursor < v.offset {
+		v.offset = v.cursor
+	}
+	if v.cursor >= v.offset+visible {
+		v.offset = v.cursor - visible + 1
+	}
+	return m, nil
+}
+
+func (m Model) loadImportFile() (tea.Model, tea.Cmd) {
+	v := m.importer
+	path := strings.TrimSpace(v.input.Value())
+	if path == "" {
+		return m, nil
+	}
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[1:])
+		}
+	}
+
+	models, format, err := interop.ImportFile(path, "")
+	if err != nil {
+		m.status.SetError("Import failed: " + err.Error())
+		return m, statusClearCmd()
+	}
+	if len(models) == 0 {
+		m.status.SetWarning("No models found in " + path)
+		return m, statusClearCmd()
+	}
+
+	v.source = path
+	v.format = format
+	v.candidates = interop.Preview(models, m.list.GetModels())
+	v.selected = make([]bool, len(v.candidates))
+	for i, c := range v.candidates {
+		v.selected[i] = !c.Duplicate()
+	}
+	v.cursor = 0
+	v.offset = 0
+	v.input.Blur()
+	return m, nil
+}
+
+// importMsg is sent when importing the selected models was confirmed.
+type importMsg struct {
+	keepKeyRefs bool
+}
+
+// confirmImport imports the selected models, first asking what to do with
+// API keys that would be read from a file or a command.
+func (m Model) confirmImport() (tea.Model, tea.Cmd) {
+	v := m.importer
+
+	var refs []string
+	for i, c := range v.candidates {
+		if v.selected[i] && c.KeyRef != "" {
+			refs = append(refs, fmt.Sprintf("%s: %s", c.Model.DisplayName, c.KeyRef))
+		}
+	}
+	if len(refs) == 0 {
+		return m.importSelected(false)
+	}
+
+	message := fmt.Sprintf("%d selected model(s) read their API key from a file or a command:\n\n%s\n\n"+
+		"The command runs, or the file is read, on every connection test and model fetch.\n"+
+		"Only keep these if you trust the source.", len(refs), strings.Join(refs, "\n"))
+	m.dialog.Show("API KEY REFERENCES", message,
+		components.DialogButton{Label: buttonLabel("Use env: placeholders", Keys.Enter), Msg: components.Reply(importMsg{})},
+		components.DialogButton{Label: "Keep references", Msg: components.Reply(importMsg{keepKeyRefs: true})},
+		components.DialogButton{Label: buttonLabel("Cancel", Keys.Escape), Cancel: true},
+	)
+	return m, nil
+}
+
+func (m Model) importSelected(keepKeyRefs bool) (tea.Model, tea.Cmd) {
+	v := m.importer
+	if v == nil {
+		return m, nil
+	}
+
+	var chosen []config.CustomModel
+	for i, c := range v.candidates {
+		if !v.selected[i] {
+			continue
+		}
+		if keepKeyRefs {
+			chosen = append(chosen, c.KeepKeyRef())
+		} else {
+			chosen = append(chosen, c.Model)
+		}
+	}
+	if len(chosen) == 0 {
+		m.status.SetWarning("No models selected")
+		return m, statusClearCmd()
+	}
+
+	m.history.push(m.snapshot())
+	m.list.ClearFilter()
+	for _, model := range chosen {
+		m.list.AddModel(model)
+	}
+	if currentModel := m.list.CurrentModel(); currentModel != nil {
+		m.form.LoadModel(currentModel)
+	}
+	m.importer = nil
+	m.dirty = true
+	m.status.SetSuccess(fmt.Sprintf("Imported %d model(s)", len(chosen)))
+	return m.saveConfig()
+}
+
+func (m Model) importVisibleLines() int {
+	return max(1, m.contentHeight-2-4)
+}
+
+func (m Model) renderImport() string {
+	v := m.importer
+	width := max(1, m.width-4)
+
+	var lines []string
+	if !v.previewing() {
+		v.input.Width = max(1, width-lipgloss.Width(v.input.Prompt)-1)
+		lines = []string{
+			TitleBackgroundStyle.Render(padOrTruncate("IMPORT MODELS", max(0, width-2))),
+			"",
+			padOrTruncate("File to import (.env, LiteLLM, Continue or aider config):", width),
+			v.input.View(),
+		}
+	} else {
+		title := fmt.Sprintf("IMPORT FROM %s (%s)", config.DisplayPath(v.source), v.format)
+		lines = []string{
+			TitleBackgroundStyle.Render(padOrTruncate(title, max(0, width-2))),
+			"",
+		}
+
+		dupStyle := lipgloss.NewStyle().Foreground(warningColor)
+		end := min(len(v.candidates), v.offset+m.importVisibleLines())
+		for i := v.offset; i < end; i++ {
+			c := v.candidates[i]
+			checkbox := CheckboxUnchecked
+			if v.selected[i] {
+				checkbox = CheckboxChecked
+			}
+			badge := ProviderBadges[c.Model.Provider]
+			text := fmt.Sprintf("%s %s %s  %s  %s", checkbox, badge, c.Model.DisplayName, c.Model.Model, c.Model.BaseURL)
+			if c.Duplicate() {
+				text += "  " + dupStyle.Render("duplicate of "+c.DuplicateOf)
+			}
+			if c.KeyRef != "" {
+				kind, _ := config.ParseSecretRef(c.KeyRef)
+				text += "  " + dupStyle.Render("API key from "+kind.Describe()+": "+c.KeyRef)
+			}
+			text = padOrTruncate(text, width)
+			if i == v.cursor {
+				text = lipgloss.NewStyle().Bold(true).Reverse(true).Render(text)
+			}
+			lines = append(lines, text)
+		}
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(primaryColor).
+		Width(max(0, m.width-2)).
+		Height(max(0, m.contentHeight-2)).
+		Padding(0, 1).
+		Render(strings.Join(lines, "\n"))
+}
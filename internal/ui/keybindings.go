@@ -1,91 +1,393 @@
 package ui
 
-import "github.com/charmbracelet/bubbles/key"
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/diogo/droid-config/internal/config"
+	"github.com/diogo/droid-config/internal/ui/components"
+)
 
 type KeyMap struct {
-	Quit       key.Binding
-	Tab        key.Binding
-	ShiftTab   key.Binding
-	Up         key.Binding
-	Down       key.Binding
-	Enter      key.Binding
-	Escape     key.Binding
-	NewModel   key.Binding
-	Delete     key.Binding
-	SelectAll  key.Binding
-	Save       key.Binding
-	MoveUp     key.Binding
-	MoveDown   key.Binding
-	Space      key.Binding
-	Confirm    key.Binding
-	Cancel     key.Binding
-}
-
-var Keys = KeyMap{
-	Quit: key.NewBinding(
-		key.WithKeys("ctrl+c"),
-		key.WithHelp("ctrl+c", "quit"),
-	),
-	Tab: key.NewBinding(
-		key.WithKeys("tab"),
-		key.WithHelp("tab", "next field"),
-	),
-	ShiftTab: key.NewBinding(
-		key.WithKeys("shift+tab"),
-		key.WithHelp("shift+tab", "prev field"),
-	),
-	Up: key.NewBinding(
-		key.WithKeys("up"),
-		key.WithHelp("up", "move up"),
-	),
-	Down: key.NewBinding(
-		key.WithKeys("down"),
-		key.WithHelp("down", "move down"),
-	),
-	Enter: key.NewBinding(
-		key.WithKeys("enter"),
-		key.WithHelp("enter", "select/confirm"),
-	),
-	Escape: key.NewBinding(
-		key.WithKeys("esc"),
-		key.WithHelp("esc", "cancel/back"),
-	),
-	NewModel: key.NewBinding(
-		key.WithKeys("n"),
-		key.WithHelp("n", "new model"),
-	),
-	Delete: key.NewBinding(
-		key.WithKeys("d"),
-		key.WithHelp("d", "delete"),
-	),
-	SelectAll: key.NewBinding(
-		key.WithKeys("a"),
-		key.WithHelp("a", "select all"),
-	),
-	Save: key.NewBinding(
-		key.WithKeys("ctrl+s"),
-		key.WithHelp("ctrl+s", "save"),
-	),
-	MoveUp: key.NewBinding(
-		key.WithKeys("ctrl+up"),
-		key.WithHelp("ctrl+up", "move up"),
-	),
-	MoveDown: key.NewBinding(
-		key.WithKeys("ctrl+down"),
-		key.WithHelp("ctrl+down", "move down"),
-	),
-	Space: key.NewBinding(
-		key.WithKeys(" "),
-		key.WithHelp("space", "toggle select"),
-	),
-	Confirm: key.NewBinding(
-		key.WithKeys("y"),
-		key.WithHelp("y", "yes"),
-	),
-	Cancel: key.NewBinding(
-		key.WithKeys("n", "esc"),
-		key.WithHelp("n/esc", "no/cancel"),
-	),
+	Quit      key.Binding
+	Tab       key.Binding
+	ShiftTab  key.Binding
+	Up        key.Binding
+	Down      key.Binding
+	Left      key.Binding
+	Right     key.Binding
+	PrevField key.Binding // moves between fields or matches while typing
+	NextField key.Binding
+	Enter     key.Binding
+	Escape    key.Binding
+	Close     key.Binding
+	NewModel  key.Binding
+	Delete    key.Binding
+	Clone     key.Binding
+	Rename    key.Binding
+	BulkEdit  key.Binding
+	SelectAll key.Binding
+	Save      key.Binding
+	MoveUp    key.Binding
+	MoveDown  key.Binding
+	Space     key.Binding
+	Confirm   key.Binding
+	Cancel    key.Binding
+	Undo      key.Binding
+	Redo      key.Binding
+	ToggleKey key.Binding
+	Test      key.Binding
+	PickModel key.Binding
+	Refresh   key.Binding
+	Search    key.Binding
+	NextMatch key.Binding
+	PrevMatch key.Binding
+	ViewMode  key.Binding
+	ApplySort key.Binding
+	Settings  key.Binding
+	Backups   key.Binding
+	Import    key.Binding
+	Export    key.Binding
+	Diff      key.Binding
+	Restore   key.Binding
+	Edit      key.Binding
+	EditJSON  key.Binding
+	Add       key.Binding
+	Reload    key.Binding
+	Overwrite key.Binding
+	Merge     key.Binding
+	SaveEdits key.Binding
+	Discard   key.Binding
+	Keep      key.Binding
+}
+
+// Keys is the key map in use. main replaces it with the one loaded from the
+// key bindings file before the program starts.
+var Keys = DefaultKeyMap()
+
+// DefaultKeyMap returns the built-in key bindings.
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Quit:      newBinding("quit", "ctrl+c"),
+		Tab:       newBinding("next field", "tab"),
+		ShiftTab:  newBinding("prev field", "shift+tab"),
+		Up:        newBinding("up", "up", "k"),
+		Down:      newBinding("down", "down", "j"),
+		Left:      newBinding("left", "left", "h"),
+		Right:     newBinding("right", "right", "l"),
+		PrevField: newBinding("prev field", "up", "ctrl+p"),
+		NextField: newBinding("next field", "down", "ctrl+n"),
+		Enter:     newBinding("select/confirm", "enter"),
+		Escape:    newBinding("cancel/back", "esc"),
+		Close:     newBinding("close", "q"),
+		NewModel:  newBinding("new model", "n"),
+		Delete:    newBinding("delete", "d"),
+		Clone:     newBinding("clone", "c"),
+		Rename:    newBinding("rename", "r"),
+		BulkEdit:  newBinding("bulk edit selected", "e"),
+		SelectAll: newBinding("select all", "a"),
+		Save:      newBinding("save", "ctrl+s"),
+		MoveUp:    newBinding("move up", "ctrl+up"),
+		MoveDown:  newBinding("move down", "ctrl+down"),
+		Space:     newBinding("toggle select", " "),
+		Confirm:   newBinding("yes", "y", "Y"),
+		Cancel:    newBinding("no", "n", "N"),
+		Undo:      newBinding("undo", "ctrl+z"),
+		Redo:      newBinding("redo", "ctrl+y"),
+		ToggleKey: newBinding("show/hide API key", "ctrl+v"),
+		Test:      newBinding("test connection", "ctrl+t"),
+		PickModel: newBinding("pick model ID", "ctrl+l"),
+		Refresh:   newBinding("refresh model IDs", "ctrl+r"),
+		Search:    newBinding("search", "/"),
+		NextMatch: newBinding("next match", "n"),
+		PrevMatch: newBinding("previous match", "N"),
+		ViewMode:  newBinding("sort/group view", "v"),
+		ApplySort: newBinding("apply sort", "S"),
+		Settings:  newBinding("other settings", "s"),
+		Backups:   newBinding("backups", "b"),
+		Import:    newBinding("import", "i"),
+		Export:    newBinding("export", "x"),
+		Diff:      newBinding("show changes", "d"),
+		Restore:   newBinding("restore backup", "r"),
+		Edit:      newBinding("edit", "e"),
+		EditJSON:  newBinding("edit as JSON", "E"),
+		Add:       newBinding("add", "a"),
+		Reload:    newBinding("reload", "r"),
+		Overwrite: newBinding("overwrite", "o"),
+		Merge:     newBinding("merge", "m"),
+		SaveEdits: newBinding("save edits", "s", "S", "y", "Y"),
+		Discard:   newBinding("discard edits", "d", "D", "n", "N"),
+		Keep:      newBinding("keep editing", "c", "C"),
+	}
+}
+
+// dialogKeys returns the keys dialogs use from the key map.
+func (k KeyMap) dialogKeys() components.DialogKeyMap {
+	return components.DialogKeyMap{
+		Next:   k.Tab,
+		Prev:   k.ShiftTab,
+		Right:  k.Right,
+		Left:   k.Left,
+		Choose: k.Enter,
+		Cancel: k.Escape,
+	}
+}
+
+func newBinding(desc string, keys ...string) key.Binding {
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(keyHelp(keys), desc))
+}
+
+// keyNames are the short forms of key names shown in the help.
+var keyNames = strings.NewReplacer("up", "↑", "down", "↓", "left", "←", "right", "→")
+
+// keyHelp joins keys for the help, e.g. "↑/k".
+func keyHelp(keys []string) string {
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		if k == " " {
+			names[i] = "space"
+		} else {
+			names[i] = keyNames.Replace(k)
+		}
+	}
+	return strings.Join(names, "/")
+}
+
+// keyPresets are the bindings the key bindings file can start from. They
+// replace the keys of some actions and keep the defaults of the others.
+var keyPresets = map[string]map[string][]string{
+	"default": {},
+	"vim": {
+		"prev_field": {"up", "ctrl+p", "ctrl+k"},
+		"next_field": {"down", "ctrl+n", "ctrl+j"},
+		"redo":       {"ctrl+r", "ctrl+y"},
+		"move_up":    {"ctrl+up", "K"},
+		"move_down":  {"ctrl+down", "J"},
+	},
+	"emacs": {
+		"up":         {"up", "ctrl+p"},
+		"down":       {"down", "ctrl+n"},
+		"left":       {"left", "ctrl+b"},
+		"right":      {"right", "ctrl+f"},
+		"escape":     {"esc", "ctrl+g"},
+		"undo":       {"ctrl+z", "ctrl+_"},
+		"move_up":    {"ctrl+up", "alt+p"},
+		"move_down":  {"ctrl+down", "alt+n"},
+		"select_all": {"a", "ctrl+a"},
+	},
+}
+
+// namedBinding is a binding with the action name used in the key bindings file.
+type namedBinding struct {
+	name    string
+	binding *key.Binding
+}
+
+func (k *KeyMap) actions() []namedBinding {
+	return []namedBinding{
+		{"quit", &k.Quit},
+		{"tab", &k.Tab},
+		{"shift_tab", &k.ShiftTab},
+		{"up", &k.Up},
+		{"down", &k.Down},
+		{"left", &k.Left},
+		{"right", &k.Right},
+		{"prev_field", &k.PrevField},
+		{"next_field", &k.NextField},
+		{"enter", &k.Enter},
+		{"escape", &k.Escape},
+		{"close", &k.Close},
+		{"new_model", &k.NewModel},
+		{"delete", &k.Delete},
+		{"clone", &k.Clone},
+		{"rename", &k.Rename},
+		{"bulk_edit", &k.BulkEdit},
+		{"select_all", &k.SelectAll},
+		{"save", &k.Save},
+		{"move_up", &k.MoveUp},
+		{"move_down", &k.MoveDown},
+		{"space", &k.Space},
+		{"confirm", &k.Confirm},
+		{"cancel", &k.Cancel},
+		{"undo", &k.Undo},
+		{"redo", &k.Redo},
+		{"toggle_key", &k.ToggleKey},
+		{"test", &k.Test},
+		{"pick_model", &k.PickModel},
+		{"refresh", &k.Refresh},
+		{"search", &k.Search},
+		{"next_match", &k.NextMatch},
+		{"prev_match", &k.PrevMatch},
+		{"view_mode", &k.ViewMode},
+		{"apply_sort", &k.ApplySort},
+		{"settings", &k.Settings},
+		{"backups", &k.Backups},
+		{"import", &k.Import},
+		{"export", &k.Export},
+		{"diff", &k.Diff},
+		{"restore", &k.Restore},
+		{"edit", &k.Edit},
+		{"edit_json", &k.EditJSON},
+		{"add", &k.Add},
+		{"reload", &k.Reload},
+		{"overwrite", &k.Overwrite},
+		{"merge", &k.Merge},
+		{"save_edits", &k.SaveEdits},
+		{"discard", &k.Discard},
+		{"keep_editing", &k.Keep},
+	}
+}
+
+// bind replaces the keys of an action. No keys disable it.
+func (k *KeyMap) bind(action string, keys []string) error {
+	for _, a := range k.actions() {
+		if a.name == action {
+			*a.binding = newBinding(a.binding.Help().Desc, keys...)
+			a.binding.SetEnabled(len(keys) > 0)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown action %q", action)
+}
+
+// keyContext lists the actions that are matched against the same key press.
+// In a text context single characters are typed into a field, so binding one
+// would make it impossible to type.
+type keyContext struct {
+	name    string
+	text    bool
+	actions []string
+}
+
+var (
+	globalActions = []string{"quit", "tab", "shift_tab", "escape", "save", "undo", "redo"}
+	listActions   = []string{"up", "down", "enter", "space", "search", "select_all", "delete", "clone", "rename",
+		"bulk_edit", "backups", "import", "export", "settings", "view_mode", "apply_sort", "move_up", "move_down"}
+	formActions   = []string{"toggle_key", "test", "pick_model"}
+	dialogActions = []string{"quit", "tab", "shift_tab", "left", "right", "enter", "escape"}
+)
+
+var keyContexts = []keyContext{
+	{name: "model list", actions: slices.Concat(globalActions, listActions, []string{"new_model"})},
+	{name: "filtered model list", actions: slices.Concat(globalActions, listActions, []string{"next_match", "prev_match"})},
+	{name: "form", text: true, actions: slices.Concat(globalActions, formActions, []string{"prev_field", "next_field", "enter"})},
+	{name: "provider selector", actions: slices.Concat(globalActions, formActions, []string{"up", "down", "left", "right"})},
+	{name: "search", text: true, actions: []string{"quit", "escape", "enter", "prev_field", "next_field"}},
+	{name: "model picker", text: true, actions: []string{"quit", "escape", "enter", "prev_field", "next_field", "refresh"}},
+	{name: "backups", actions: []string{"quit", "escape", "close", "backups", "up", "down", "enter", "diff", "restore"}},
+	{name: "settings", actions: []string{"quit", "escape", "close", "settings", "up", "down", "left", "right", "enter",
+		"space", "edit", "edit_json", "add", "delete", "undo", "redo"}},
+	{name: "settings editor", text: true, actions: []string{"quit", "escape", "enter", "save"}},
+	{name: "import", actions: []string{"quit", "escape", "up", "down", "space", "select_all", "enter"}},
+	{name: "import path", text: true, actions: []string{"quit", "escape", "enter"}},
+	{name: "export path", text: true, actions: []string{"quit", "escape", "tab", "shift_tab", "enter"}},
+	{name: "bulk edit", text: true, actions: []string{"quit", "escape", "tab", "shift_tab", "prev_field", "next_field", "enter"}},
+	{name: "bulk edit provider", actions: []string{"quit", "escape", "tab", "shift_tab", "prev_field", "next_field", "enter",
+		"left", "right", "space"}},
+	{name: "bulk edit preview", actions: []string{"quit", "escape", "up", "down", "enter"}},
+	{name: "recovery screen", actions: []string{"quit", "close", "reload", "overwrite"}},
+	{name: "confirmation", actions: slices.Concat(dialogActions, []string{"confirm", "cancel"})},
+	{name: "unsaved changes prompt", actions: slices.Concat(dialogActions, []string{"save_edits", "discard", "keep_editing"})},
+	{name: "file changed prompt", actions: slices.Concat(dialogActions, []string{"reload", "overwrite", "merge"})},
+	{name: "text prompt", text: true, actions: []string{"quit", "tab", "shift_tab", "enter", "escape"}},
+}
+
+// Conflicts describes every key bound to two actions that are matched
+// against the same key press, and every character bound where it would be
+// typed into a field.
+func (k *KeyMap) Conflicts() []string {
+	bindings := make(map[string]*key.Binding)
+	for _, a := range k.actions() {
+		bindings[a.name] = a.binding
+	}
+
+	// The same clash usually shows up in several contexts; report it once,
+	// naming all of them.
+	var problems []string
+	where := make(map[string][]string)
+	report := func(problem, ctx string) {
+		if _, ok := where[problem]; !ok {
+			problems = append(problems, problem)
+		}
+		where[problem] = append(where[problem], ctx)
+	}
+	for _, ctx := range keyContexts {
+		owner := make(map[string]string)
+		for _, action := range ctx.actions {
+			b := bindings[action]
+			if !b.Enabled() {
+				continue
+			}
+			for _, name := range b.Keys() {
+				if other, ok := owner[name]; ok && other != action {
+					report(fmt.Sprintf("%q is bound to both %s and %s", name, other, action), ctx.name)
+					continue
+				}
+				owner[name] = action
+				if ctx.text && len([]rune(name)) == 1 {
+					report(fmt.Sprintf("%q is bound to %s, but is typed as text", name, action), ctx.name)
+				}
+			}
+		}
+	}
+
+	conflicts := make([]string, len(problems))
+	for i, p := range problems {
+		conflicts[i] = p + " in: " + strings.Join(where[p], ", ")
+	}
+	return conflicts
+}
+
+// LoadKeyMap builds the key map from the key bindings file at path: the
+// defaults, then the file's preset, then its bindings. A missing file yields
+// the defaults. Unknown presets or actions and conflicting keys are errors.
+func LoadKeyMap(path string) (KeyMap, error) {
+	km := DefaultKeyMap()
+	kb, err := config.LoadKeyBindings(path)
+	if err != nil {
+		return km, err
+	}
+
+	var errs []error
+	if kb.Preset != "" {
+		preset, ok := keyPresets[kb.Preset]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown preset %q (choose from %s)", kb.Preset, strings.Join(sortedKeys(keyPresets), ", ")))
+		}
+		for _, action := range sortedKeys(preset) {
+			if err := km.bind(action, preset[action]); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	for _, action := range sortedKeys(kb.Bindings) {
+		if err := km.bind(action, kb.Bindings[action]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) == 0 {
+		for _, c := range km.Conflicts() {
+			errs = append(errs, errors.New(c))
+		}
+	}
+	if len(errs) > 0 {
+		return DefaultKeyMap(), errors.Join(errs...)
+	}
+	return km, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
 func (k KeyMap) ShortHelp() []key.Binding {
@@ -95,8 +397,11 @@ func (k KeyMap) ShortHelp() []key.Binding {
 func (k KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Tab, k.ShiftTab, k.Up, k.Down},
-		{k.NewModel, k.Delete, k.SelectAll},
+		{k.NewModel, k.Clone, k.Rename, k.BulkEdit, k.Delete, k.SelectAll},
 		{k.Save, k.MoveUp, k.MoveDown},
+		{k.Undo, k.Redo, k.ToggleKey, k.Test, k.PickModel},
+		{k.Search, k.NextMatch, k.PrevMatch},
+		{k.ViewMode, k.ApplySort, k.Settings, k.Backups, k.Import, k.Export},
 		{k.Quit, k.Escape},
 	}
 }
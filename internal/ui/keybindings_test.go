@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/diogo/droid-config/internal/config"
+)
+
+func writeKeyBindings(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), config.KeysFileName)
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadKeyMapMissingFile(t *testing.T) {
+	km, err := LoadKeyMap(filepath.Join(t.TempDir(), config.KeysFileName))
+	if err != nil {
+		t.Fatalf("Expected a missing file to be fine, got %v", err)
+	}
+	if !reflect.DeepEqual(km, DefaultKeyMap()) {
+		t.Error("Expected the default key map for a missing file")
+	}
+}
+
+func TestKeyPresetsHaveNoConflicts(t *testing.T) {
+	for _, name := range sortedKeys(keyPresets) {
+		t.Run(name, func(t *testing.T) {
+			km, err := LoadKeyMap(writeKeyBindings(t, `{"preset": "`+name+`"}`))
+			if err != nil {
+				t.Fatalf("Expected preset %s to load, got %v", name, err)
+			}
+			for action, keys := range keyPresets[name] {
+				for _, a := range km.actions() {
+					if a.name == action && !slices.Equal(a.binding.Keys(), keys) {
+						t.Errorf("Expected %s bound to %v, got %v", action, keys, a.binding.Keys())
+					}
+				}
+			}
+		})
+	}
+
+	km := DefaultKeyMap()
+	if conflicts := km.Conflicts(); len(conflicts) > 0 {
+		t.Errorf("Expected no conflicts in the defaults, got %v", conflicts)
+	}
+}
+
+func TestLoadKeyMapRejectsBadBindings(t *testing.T) {
+	tests := map[string]struct {
+		data string
+		want []string
+	}{
+		"unknown preset":         {`{"preset": "nano"}`, []string{`unknown preset "nano"`}},
+		"unknown action":         {`{"bindings": {"launch": "ctrl+l"}}`, []string{`unknown action "launch"`}},
+		"same key in the list":   {`{"bindings": {"delete": "c"}}`, []string{`"c" is bound to both`, "delete", "clone", "model list"}},
+		"character in the form":  {`{"bindings": {"toggle_key": "x"}}`, []string{`"x" is bound to toggle_key, but is typed as text`, "form"}},
+		"character in search":    {`{"bindings": {"next_field": ["down", "j"]}}`, []string{`"j" is bound to next_field, but is typed as text`, "search"}},
+		"same key in a dialog":   {`{"bindings": {"merge": "r"}}`, []string{`"r" is bound to both reload and merge`, "file changed prompt"}},
+		"escape as dialog quit":  {`{"bindings": {"quit": ["ctrl+c", "esc"]}}`, []string{`"esc" is bound to both quit and escape`, "confirmation"}},
+		"preset and bad binding": {`{"preset": "vim", "bindings": {"rename": "c"}}`, []string{`"c" is bound to both`, "rename"}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			km, err := LoadKeyMap(writeKeyBindings(t, tt.data))
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Expected the error to mention %q, got %v", want, err)
+				}
+			}
+			if !reflect.DeepEqual(km, DefaultKeyMap()) {
+				t.Error("Expected the default key map after an error")
+			}
+		})
+	}
+}
@@ -1,9 +1,14 @@
 package ui
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/diogo/droid-config/internal/config"
@@ -19,10 +24,12 @@ const (
 
 type Model struct {
 	config        *config.ConfigData
+	configPath    string
 	list          *components.List
 	form          *components.Form
 	status        *components.Status
-	confirm       *components.Confirm
+	dialog        *components.Dialog
+	picker        *components.Picker
 	help          help.Model
 	focusArea     FocusArea
 	width         int
@@ -36,10 +43,26 @@ type Model struct {
 	ready         bool
 	quitting      bool
 	dirty         bool
+	loadErr       error
+	forceSave     bool
+	backups       *backupsView
+	importer      *importView
+	exporter      *exportView
+	bulkEdit      *bulkEditView
+	settings      *settingsView
+	history       *history
+	testing       bool
+	modelCache    map[string][]string
+	diskState     config.FileState     // file content the list was last synced with
+	ignoredState  config.FileState     // external change the user chose to ignore
+	baseModels    []config.CustomModel // models as last loaded or saved, for merging
+	baseExtra     map[string]json.RawMessage
+	external      *externalChange
+	pending       tea.Msg // key or click to handle once unsaved form edits are saved or discarded
 }
 
-func NewModel() Model {
-	cfg, _ := config.Load()
+func NewModel(configPath string) Model {
+	cfg, err := config.Load(configPath)
 	if cfg == nil {
 		cfg = &config.ConfigData{CustomModels: []config.CustomModel{}}
 	}
@@ -52,16 +75,76 @@ func NewModel() Model {
 		form.LoadModel(&cfg.CustomModels[0])
 	}
 
-	return Model{
-		config:    cfg,
-		list:      list,
-		form:      form,
-		status:    components.NewStatus(),
-		confirm:   components.NewConfirm(),
-		help:      help.New(),
-		focusArea: FocusSidebar,
-		ready:     false,
+	m := Model{
+		config:     cfg,
+		configPath: configPath,
+		list:       list,
+		form:       form,
+		status:     components.NewStatus(),
+		dialog:     components.NewDialog(),
+		picker:     components.NewPicker(),
+		help:       help.New(),
+		focusArea:  FocusSidebar,
+		ready:      false,
+		loadErr:    err,
+		history:    newHistory(),
+		modelCache: make(map[string][]string),
 	}
+	m.dialog.Keys = Keys.dialogKeys()
+	m.markSynced()
+	return m
+}
+
+func (m Model) snapshot() snapshot {
+	return snapshot{models: m.list.GetModels(), cursor: m.list.Cursor, extra: m.config.Extra()}
+}
+
+// restoreSnapshot replaces the list with s and persists it.
+func (m Model) restoreSnapshot(s snapshot, message string) (tea.Model, tea.Cmd) {
+	m.list.SetItems(s.models)
+	m.list.SetCursor(s.cursor)
+	m.config.SetExtra(s.extra)
+	m.reloadSettings()
+	if currentModel := m.list.CurrentModel(); currentModel != nil {
+		m.form.LoadModel(currentModel)
+	} else {
+		m.form.LoadModel(nil)
+	}
+	m.dirty = true
+	m.status.SetInfo(message)
+	return m.saveConfig()
+}
+
+func (m Model) undo() (tea.Model, tea.Cmd) {
+	s, ok := m.history.Undo(m.snapshot())
+	if !ok {
+		m.status.SetInfo("Nothing to undo")
+		return m, statusClearCmd()
+	}
+	return m.restoreSnapshot(s, "Undone")
+}
+
+func (m Model) redo() (tea.Model, tea.Cmd) {
+	s, ok := m.history.Redo(m.snapshot())
+	if !ok {
+		m.status.SetInfo("Nothing to redo")
+		return m, statusClearCmd()
+	}
+	return m.restoreSnapshot(s, "Redone")
+}
+
+// setConfig replaces the loaded config and resets the list and form to it.
+func (m *Model) setConfig(cfg *config.ConfigData) {
+	m.config = cfg
+	m.list.SetItems(cfg.CustomModels)
+	m.list.ClearSelections()
+	if currentModel := m.list.CurrentModel(); currentModel != nil {
+		m.form.LoadModel(currentModel)
+	} else {
+		m.form.LoadModel(nil)
+	}
+	m.reloadSettings()
+	m.markSynced()
 }
 
 type statusClearMsg struct{}
@@ -73,7 +156,7 @@ func statusClearCmd() tea.Cmd {
 }
 
 func (m Model) Init() tea.Cmd {
-	return textinput.Blink
+	return tea.Batch(textinput.Blink, watchFileCmd(m.configPath))
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -140,10 +223,50 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.form.Height = max(1, m.formHeight-2)
 
 		m.status.Width = msg.Width
-		m.confirm.Width = min(40, max(20, msg.Width-10))
+		m.dialog.Width = min(40, max(20, msg.Width-10))
+		m.dialog.MaxWidth = max(20, msg.Width-4)
+		m.picker.Width = min(60, max(24, msg.Width-10))
+		m.picker.Height = min(24, max(10, msg.Height-4))
+		m.layoutSettings()
 		m.ready = true
 		return m, nil
 
+	case connectionTestMsg:
+		return m.handleConnectionResult(msg)
+
+	case modelsFetchedMsg:
+		return m.handleModelsFetched(msg)
+
+	case fileCheckedMsg:
+		return m.handleFileChecked(msg)
+
+	case externalChoiceMsg:
+		return m.resolveExternal(msg.action)
+
+	case unsavedChoiceMsg:
+		return m.resolveUnsaved(msg.save)
+
+	case deleteModelsMsg:
+		return m.deleteModels(msg.selected)
+
+	case renameMsg:
+		return m.renameModel(msg.name)
+
+	case importMsg:
+		return m.importSelected(msg.keepKeyRefs)
+
+	case overwriteExportMsg:
+		return m.writeExport(true)
+
+	case restoreBackupMsg:
+		return m.restoreSelectedBackup()
+
+	case deleteSettingMsg:
+		return m.deleteSetting()
+
+	case tea.MouseMsg:
+		return m.handleMouse(msg)
+
 	case statusClearMsg:
 		if m.status.IsExpired() {
 			m.status.Clear()
@@ -151,16 +274,47 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyMsg:
-		if m.confirm.Active {
-			return m.handleConfirmKeys(msg)
+		if m.loadErr != nil {
+			return m.handleRecoveryKeys(msg)
+		}
+		if m.dialog.Active {
+			if key.Matches(msg, Keys.Quit) {
+				m.quitting = true
+				return m, tea.Quit
+			}
+			return m, m.dialog.Update(msg)
+		}
+		if m.picker.Active {
+			return m.handlePickerKeys(msg)
+		}
+		if m.backups != nil {
+			return m.handleBackupsKeys(msg)
+		}
+		if m.importer != nil {
+			return m.handleImportKeys(msg)
+		}
+		if m.exporter != nil {
+			return m.handleExportKeys(msg)
+		}
+		if m.bulkEdit != nil {
+			return m.handleBulkEditKeys(msg)
+		}
+		if m.settings != nil {
+			return m.handleSettingsKeys(msg)
+		}
+		if m.list.Searching() {
+			return m.handleSearchKeys(msg)
 		}
 
-		switch msg.String() {
-		case "ctrl+c":
-			m.quitting = true
-			return m, tea.Quit
+		if m.leavesForm(msg) && m.form.Dirty() {
+			return m.promptUnsaved(msg)
+		}
+
+		switch {
+		case key.Matches(msg, Keys.Quit):
+			return m.quit(msg)
 
-		case "tab":
+		case key.Matches(msg, Keys.Tab):
 			if m.focusArea == FocusSidebar {
 				m.focusArea = FocusForm
 				m.form.Focus()
@@ -169,7 +323,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, nil
 
-		case "shift+tab":
+		case key.Matches(msg, Keys.ShiftTab):
 			if m.focusArea == FocusForm {
 				if m.form.FocusIndex() == 0 {
 					m.focusArea = FocusSidebar
@@ -180,7 +334,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, nil
 
-		case "esc":
+		case key.Matches(msg, Keys.Escape):
+			if m.focusArea == FocusSidebar && m.list.Filtering() {
+				return m.clearSearch()
+			}
 			if m.focusArea == FocusForm {
 				m.focusArea = FocusSidebar
 				m.form.Blur()
@@ -190,34 +347,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, nil
 
-		case "ctrl+s":
+		case key.Matches(msg, Keys.Save):
 			return m.saveCurrentModel()
 
-		case "ctrl+v":
-			if m.focusArea == FocusForm {
-				m.form.ToggleAPIKeyVisibility()
-			}
-			return m, nil
-
-		case "ctrl+up":
-			if m.focusArea == FocusSidebar {
-				if m.list.MoveItemUp() {
-					m.dirty = true
-					m.status.SetSuccess("Model moved up")
-					return m.saveConfig()
-				}
-			}
-			return m, nil
+		case key.Matches(msg, Keys.Undo):
+			return m.undo()
 
-		case "ctrl+down":
-			if m.focusArea == FocusSidebar {
-				if m.list.MoveItemDown() {
-					m.dirty = true
-					m.status.SetSuccess("Model moved down")
-					return m.saveConfig()
-				}
-			}
-			return m, nil
+		case key.Matches(msg, Keys.Redo):
+			return m.redo()
 		}
 
 		if m.focusArea == FocusSidebar {
@@ -227,6 +364,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	if m.dialog.Active {
+		return m, m.dialog.Update(msg)
+	}
+	if m.settings != nil {
+		return m, m.updateSettingsInput(msg)
+	}
+
 	if m.focusArea == FocusForm && m.form.FocusIndex() != components.FieldProvider {
 		if input := m.form.CurrentInput(); input != nil {
 			newInput, cmd := input.Update(msg)
@@ -239,36 +383,92 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) handleSidebarKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "up", "k":
+	filtering := m.list.Filtering()
+
+	switch {
+	case key.Matches(msg, Keys.Up):
 		m.list.MoveUp()
 		if currentModel := m.list.CurrentModel(); currentModel != nil {
 			m.form.LoadModel(currentModel)
 		}
 		return m, nil
 
-	case "down", "j":
+	case key.Matches(msg, Keys.Down):
 		m.list.MoveDown()
 		if currentModel := m.list.CurrentModel(); currentModel != nil {
 			m.form.LoadModel(currentModel)
 		}
 		return m, nil
 
-	case " ":
+	case key.Matches(msg, Keys.MoveUp):
+		return m.moveModel(-1)
+
+	case key.Matches(msg, Keys.MoveDown):
+		return m.moveModel(1)
+
+	case key.Matches(msg, Keys.Space):
 		m.list.ToggleSelected()
 		return m, nil
 
-	case "a":
-		m.list.SelectAll()
+	case key.Matches(msg, Keys.Search):
+		m.list.StartSearch()
+		return m, textinput.Blink
+
+	case filtering && key.Matches(msg, Keys.NextMatch):
+		m.list.NextMatch()
+		m.loadCurrentModel()
+		return m, nil
+
+	case filtering && key.Matches(msg, Keys.PrevMatch):
+		m.list.PrevMatch()
+		m.loadCurrentModel()
 		return m, nil
 
-	case "n":
+	case !filtering && key.Matches(msg, Keys.NewModel):
 		return m.addNewModel()
 
-	case "d":
+	case key.Matches(msg, Keys.SelectAll):
+		m.list.SelectAll()
+		return m, nil
+
+	case key.Matches(msg, Keys.Delete):
 		return m.handleDelete()
 
-	case "enter":
+	case key.Matches(msg, Keys.Clone):
+		return m.cloneModels()
+
+	case key.Matches(msg, Keys.BulkEdit):
+		return m.openBulkEdit()
+
+	case key.Matches(msg, Keys.Backups):
+		return m.openBackups()
+
+	case key.Matches(msg, Keys.Import):
+		return m.openImport()
+
+	case key.Matches(msg, Keys.Export):
+		return m.openExport()
+
+	case key.Matches(msg, Keys.Settings):
+		return m.openSettings()
+
+	case key.Matches(msg, Keys.Rename):
+		return m.promptRename()
+
+	case key.Matches(msg, Keys.ViewMode):
+		mode := m.list.CycleViewMode()
+		m.loadCurrentModel()
+		m.status.SetInfo("View: " + mode.String())
+		return m, statusClearCmd()
+
+	case key.Matches(msg, Keys.ApplySort):
+		return m.applySort()
+
+	case key.Matches(msg, Keys.Enter):
+		if m.list.OnHeader() {
+			m.list.ToggleGroup()
+			return m, nil
+		}
 		m.focusArea = FocusForm
 		m.form.Focus()
 		return m, nil
@@ -277,31 +477,73 @@ func (m Model) handleSidebarKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// moveModel moves the current model one place up (dir -1) or down (dir 1)
+// in the file.
+func (m Model) moveModel(dir int) (tea.Model, tea.Cmd) {
+	if m.list.ViewMode() != components.ViewFileOrder {
+		m.status.SetWarning("Switch to file order (" + Keys.ViewMode.Help().Key + ") to reorder models")
+		return m, statusClearCmd()
+	}
+	before := m.snapshot()
+	moved, message := m.list.MoveItemUp, "Model moved up"
+	if dir > 0 {
+		moved, message = m.list.MoveItemDown, "Model moved down"
+	}
+	if !moved() {
+		return m, nil
+	}
+	m.history.push(before)
+	m.dirty = true
+	m.status.SetSuccess(message)
+	return m.saveConfig()
+}
+
+// applySort persists the order the current view mode shows.
+func (m Model) applySort() (tea.Model, tea.Cmd) {
+	mode := m.list.ViewMode()
+	if mode == components.ViewFileOrder {
+		m.status.SetWarning("Choose a sort or grouping with " + Keys.ViewMode.Help().Key + " first")
+		return m, statusClearCmd()
+	}
+	m.history.push(m.snapshot())
+	m.list.ApplySort()
+	m.dirty = true
+	m.status.SetSuccess("Applied order: " + mode.String())
+	return m.saveConfig()
+}
+
 func (m Model) handleFormKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, Keys.ToggleKey):
+		m.form.ToggleAPIKeyVisibility()
+		return m, nil
+	case key.Matches(msg, Keys.Test):
+		return m.testConnection()
+	case key.Matches(msg, Keys.PickModel):
+		return m.openModelPicker(false)
+	}
+
 	if m.form.FocusIndex() == components.FieldProvider {
-		switch msg.String() {
-		case "left", "h":
+		switch {
+		case key.Matches(msg, Keys.Left):
 			m.form.PrevProvider()
 			return m, nil
-		case "right", "l":
+		case key.Matches(msg, Keys.Right):
 			m.form.NextProvider()
 			return m, nil
-		case "up", "k":
+		case key.Matches(msg, Keys.Up):
 			m.form.FocusPrev()
 			return m, nil
-		case "down", "j":
+		case key.Matches(msg, Keys.Down):
 			m.form.FocusNext()
 			return m, nil
 		}
 	} else {
-		switch msg.String() {
-		case "up":
+		switch {
+		case key.Matches(msg, Keys.PrevField):
 			m.form.FocusPrev()
 			return m, nil
-		case "down":
-			m.form.FocusNext()
-			return m, nil
-		case "enter":
+		case key.Matches(msg, Keys.NextField), key.Matches(msg, Keys.Enter):
 			m.form.FocusNext()
 			return m, nil
 		}
@@ -316,45 +558,92 @@ func (m Model) handleFormKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-func (m Model) handleConfirmKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "y", "Y":
-		action := m.confirm.Action
-		m.confirm.Hide()
-
-		switch action {
-		case components.ConfirmDeleteCurrent:
-			if m.list.DeleteCurrent() {
-				m.dirty = true
-				m.status.SetSuccess("Model deleted")
-				if currentModel := m.list.CurrentModel(); currentModel != nil {
-					m.form.LoadModel(currentModel)
-				} else {
-					m.form.LoadModel(nil)
-				}
-				return m.saveConfig()
-			}
-		case components.ConfirmDeleteSelected:
-			count := m.list.DeleteSelected()
-			if count > 0 {
-				m.dirty = true
-				m.status.SetSuccess("Deleted " + string(rune('0'+count)) + " model(s)")
-				if currentModel := m.list.CurrentModel(); currentModel != nil {
-					m.form.LoadModel(currentModel)
-				} else {
-					m.form.LoadModel(nil)
-				}
-				return m.saveConfig()
-			}
+// deleteModelsMsg is sent when deleting the current model, or the selected
+// ones, was confirmed.
+type deleteModelsMsg struct {
+	selected bool
+}
+
+// renameMsg is sent when the rename prompt was accepted.
+type renameMsg struct {
+	name string
+}
+
+// askConfirm shows a yes/no dialog that sends msg on yes.
+func (m Model) askConfirm(message string, msg tea.Msg) {
+	m.dialog.Show("", message,
+		components.DialogButton{Label: buttonLabel("Yes", Keys.Confirm), Key: Keys.Confirm, Msg: components.Reply(msg)},
+		components.DialogButton{Label: buttonLabel("No", Keys.Cancel), Key: Keys.Cancel, Cancel: true},
+	)
+}
+
+// buttonLabel names a dialog button after its binding, e.g. "Yes (y/Y)".
+func buttonLabel(text string, b key.Binding) string {
+	if !b.Enabled() {
+		return text
+	}
+	return text + " (" + b.Help().Key + ")"
+}
+
+func (m Model) deleteModels(selected bool) (tea.Model, tea.Cmd) {
+	before := m.snapshot()
+	if selected {
+		count := m.list.DeleteSelected()
+		if count == 0 {
+			return m, nil
 		}
+		m.status.SetSuccess(fmt.Sprintf("Deleted %d model(s)", count))
+	} else {
+		if !m.list.DeleteCurrent() {
+			return m, nil
+		}
+		m.status.SetSuccess("Model deleted")
+	}
+	m.history.push(before)
+	m.dirty = true
+	if currentModel := m.list.CurrentModel(); currentModel != nil {
+		m.form.LoadModel(currentModel)
+	} else {
+		m.form.LoadModel(nil)
+	}
+	return m.saveConfig()
+}
+
+func (m Model) promptRename() (tea.Model, tea.Cmd) {
+	current := m.list.CurrentModel()
+	if current == nil {
 		return m, nil
+	}
+	cmd := m.dialog.Prompt("RENAME", "New display name:", current.DisplayName,
+		components.DialogButton{Label: buttonLabel("Rename", Keys.Enter), Msg: func(name string) tea.Msg { return renameMsg{name: name} }},
+		components.DialogButton{Label: buttonLabel("Cancel", Keys.Escape), Cancel: true},
+	)
+	return m, cmd
+}
 
-	case "n", "N", "esc":
-		m.confirm.Hide()
+func (m Model) renameModel(name string) (tea.Model, tea.Cmd) {
+	current := m.list.CurrentModel()
+	name = strings.TrimSpace(name)
+	if current == nil || name == current.DisplayName {
 		return m, nil
 	}
 
-	return m, nil
+	renamed := *current
+	renamed.DisplayName = name
+	models := m.list.GetModels()
+	models[m.list.Cursor] = renamed
+	issues := config.ValidateModels(models)[m.list.Cursor].ForField(config.FieldDisplayName)
+	if issues.HasErrors() {
+		m.status.SetError("Display name " + issues[0].Message)
+		return m, statusClearCmd()
+	}
+
+	m.history.push(m.snapshot())
+	m.list.UpdateCurrentModel(renamed)
+	m.form.LoadModel(&renamed)
+	m.dirty = true
+	m.status.SetSuccess("Renamed to \"" + name + "\"")
+	return m.saveConfig()
 }
 
 func (m Model) addNewModel() (tea.Model, tea.Cmd) {
@@ -362,6 +651,8 @@ func (m Model) addNewModel() (tea.Model, tea.Cmd) {
 		DisplayName: "New Model",
 		Provider:    "openai",
 	}
+	m.history.push(m.snapshot())
+	m.list.ClearFilter()
 	m.list.AddModel(newModel)
 	m.form.LoadModel(&newModel)
 	m.focusArea = FocusForm
@@ -373,44 +664,97 @@ func (m Model) addNewModel() (tea.Model, tea.Cmd) {
 	return m.saveConfig()
 }
 
+// cloneModels duplicates the selected models, or the current one, right
+// below the originals.
+func (m Model) cloneModels() (tea.Model, tea.Cmd) {
+	before := m.snapshot()
+	n := m.list.CloneSelected()
+	if n == 0 {
+		return m, nil
+	}
+	m.history.push(before)
+	m.loadCurrentModel()
+	m.dirty = true
+	if n == 1 {
+		m.status.SetSuccess("Model cloned")
+	} else {
+		m.status.SetSuccess(fmt.Sprintf("Cloned %d models", n))
+	}
+	return m.saveConfig()
+}
+
 func (m Model) handleDelete() (tea.Model, tea.Cmd) {
 	selected := m.list.GetSelectedIndices()
 	if len(selected) > 0 {
-		m.confirm.Show(components.ConfirmDeleteSelected,
-			"Delete "+string(rune('0'+len(selected)))+" selected model(s)?")
-	} else if len(m.list.Items) > 0 {
-		modelName := m.list.CurrentModel().DisplayName
+		m.askConfirm(fmt.Sprintf("Delete %d selected model(s)?", len(selected)), deleteModelsMsg{selected: true})
+	} else if current := m.list.CurrentModel(); current != nil {
+		modelName := current.DisplayName
 		if modelName == "" {
 			modelName = "this model"
 		}
-		m.confirm.Show(components.ConfirmDeleteCurrent,
-			"Delete \""+modelName+"\"?")
+		m.askConfirm("Delete \""+modelName+"\"?", deleteModelsMsg{})
 	}
 	return m, nil
 }
 
 func (m Model) saveCurrentModel() (tea.Model, tea.Cmd) {
-	valid, errMsg := m.form.Validate()
+	index := -1
+	if m.list.CurrentModel() != nil {
+		index = m.list.Cursor
+	}
+	valid, errMsg, warnings := m.form.Validate(m.list.GetModels(), index)
 	if !valid {
 		m.status.SetError(errMsg)
 		return m, statusClearCmd()
 	}
 
 	updatedModel := m.form.GetModel()
+	if current := m.list.CurrentModel(); current == nil || !current.Equal(updatedModel) {
+		m.history.push(m.snapshot())
+	}
 	m.list.UpdateCurrentModel(updatedModel)
 	m.dirty = true
-	m.status.SetSuccess("Changes saved!")
+	if warnings > 0 {
+		m.status.SetWarning(fmt.Sprintf("Changes saved with %d warning(s)", warnings))
+	} else {
+		m.status.SetSuccess("Changes saved!")
+	}
 
 	return m.saveConfig()
 }
 
 func (m Model) saveConfig() (tea.Model, tea.Cmd) {
 	m.config.CustomModels = m.list.GetModels()
-	if err := config.Save(m.config); err != nil {
+
+	lock, err := config.LockFile(m.configPath, 0)
+	if err != nil {
+		m.dirty = true
+		if errors.Is(err, config.ErrLocked) {
+			m.status.SetError("Not saved: another process is writing the config - press " + Keys.Save.Help().Key + " to retry")
+		} else {
+			m.status.SetError("Failed to save: " + err.Error())
+		}
+		return m, statusClearCmd()
+	}
+	defer lock.Unlock()
+
+	// Never clobber changes another program made since we last synced.
+	if state, err := config.StatFile(m.configPath); err == nil && state.Changed(m.diskState) {
+		m.openExternalChange(state, true)
+		return m, nil
+	}
+
+	if err := config.SaveWith(m.configPath, m.config, config.SaveOptions{Force: m.forceSave, Locked: true}); err != nil {
 		m.status.SetError("Failed to save: " + err.Error())
 		return m, statusClearCmd()
 	}
 	m.dirty = false
+	m.forceSave = false
+	m.markSynced()
+	// The form is saved once what it shows is written.
+	if current := m.list.CurrentModel(); current != nil && current.Equal(m.form.GetModel()) {
+		m.form.MarkClean()
+	}
 	return m, statusClearCmd()
 }
 
@@ -0,0 +1,111 @@
+package ui
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diogo/droid-config/internal/config"
+	"github.com/diogo/droid-config/internal/endpoint"
+	"github.com/diogo/droid-config/internal/ui/components"
+)
+
+type modelsFetchedMsg struct {
+	baseURL string
+	ids     []string
+	err     error
+}
+
+func fetchModelsCmd(m config.CustomModel) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), connectionTestTimeout)
+		defer cancel()
+		client := &http.Client{Timeout: connectionTestTimeout}
+		ids, err := endpoint.ListModels(ctx, client, m)
+		return modelsFetchedMsg{baseURL: modelCacheKey(m.BaseURL), ids: ids, err: err}
+	}
+}
+
+func modelCacheKey(baseURL string) string {
+	return strings.TrimRight(strings.TrimSpace(baseURL), "/")
+}
+
+// openModelPicker shows the provider's model IDs, fetching them unless they
+// are cached for the form's base URL or refresh is set.
+func (m Model) openModelPicker(refresh bool) (tea.Model, tea.Cmd) {
+	model := m.form.GetModel()
+	baseURL := modelCacheKey(model.BaseURL)
+	if baseURL == "" {
+		m.status.SetError("Set a Base URL before listing models")
+		return m, statusClearCmd()
+	}
+
+	if ids, ok := m.modelCache[baseURL]; ok && !refresh {
+		m.picker.Show("Models at "+baseURL, ids)
+		return m, nil
+	}
+
+	m.status.Set("Fetching models from "+baseURL+"...", components.StatusInfo, connectionTestTimeout)
+	return m, fetchModelsCmd(model)
+}
+
+func (m Model) handleModelsFetched(msg modelsFetchedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.status.Set("Failed to list models: "+msg.err.Error(), components.StatusError, 10*time.Second)
+		return m, statusClearCmd()
+	}
+
+	m.modelCache[msg.baseURL] = msg.ids
+	m.status.Clear()
+
+	// Ignore late results when the user has moved on to another endpoint.
+	if m.focusArea != FocusForm || modelCacheKey(m.form.GetModel().BaseURL) != msg.baseURL {
+		return m, nil
+	}
+	if len(msg.ids) == 0 {
+		m.status.SetWarning("Endpoint returned no models")
+		return m, statusClearCmd()
+	}
+	m.picker.Show("Models at "+msg.baseURL, msg.ids)
+	return m, nil
+}
+
+func (m Model) handlePickerKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, Keys.Quit):
+		return m.quit(msg)
+
+	case key.Matches(msg, Keys.Escape):
+		m.picker.Hide()
+		return m, nil
+
+	case key.Matches(msg, Keys.PrevField):
+		m.picker.MoveUp()
+		return m, nil
+
+	case key.Matches(msg, Keys.NextField):
+		m.picker.MoveDown()
+		return m, nil
+
+	case key.Matches(msg, Keys.Refresh):
+		m.picker.Hide()
+		return m.openModelPicker(true)
+
+	case key.Matches(msg, Keys.Enter):
+		if id := m.picker.Selected(); id != "" {
+			m.form.SetFieldValue(components.FieldModelID, id)
+			m.form.SetFocusIndex(components.FieldModelID)
+			m.status.SetSuccess("Model ID set to " + id)
+		}
+		m.picker.Hide()
+		return m, statusClearCmd()
+	}
+
+	input := m.picker.FilterInput()
+	newInput, cmd := input.Update(msg)
+	m.picker.UpdateFilter(newInput)
+	return m, cmd
+}
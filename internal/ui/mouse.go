@@ -0,0 +1,78 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Panels have a one-cell border and one column of padding on each side.
+const (
+	panelInsetX = 2
+	panelInsetY = 1
+)
+
+// handleMouse routes mouse events on the main view to the list or the form.
+// Dialogs take clicks on their buttons; the other overlays ignore the mouse.
+func (m Model) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if m.dialog.Active {
+		return m, m.dialog.HandleMouse(msg, m.width, m.height)
+	}
+	if m.loadErr != nil || m.picker.Active || m.backups != nil || m.importer != nil ||
+		m.exporter != nil || m.bulkEdit != nil || m.settings != nil || m.list.Searching() {
+		return m, nil
+	}
+
+	listX, listY := msg.X-panelInsetX, msg.Y-panelInsetY
+	if m.list.Dragging() {
+		m.list.HandleMouse(msg, listX, listY)
+		return m, nil
+	}
+
+	formLeft, formTop := m.sidebarWidth, 0
+	if m.stackedLayout {
+		formLeft, formTop = 0, m.sidebarHeight
+	}
+	switch {
+	case msg.X < m.sidebarWidth && msg.Y < m.sidebarHeight:
+		return m.handleListMouse(msg, listX, listY)
+	case msg.X >= formLeft && msg.Y >= formTop && msg.Y < formTop+m.formHeight:
+		return m.handleFormMouse(msg, msg.X-formLeft-panelInsetX, msg.Y-formTop-panelInsetY)
+	}
+	return m, nil
+}
+
+func (m Model) handleListMouse(msg tea.MouseMsg, x, y int) (tea.Model, tea.Cmd) {
+	click := msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft
+	if click && m.focusArea == FocusForm && m.list.RowAt(y) >= 0 && !m.list.ScrollbarAt(x, y) {
+		// Clicking a row leaves the form, like esc.
+		if m.form.Dirty() {
+			return m.promptUnsaved(msg)
+		}
+		m.focusArea = FocusSidebar
+		m.form.Blur()
+	}
+	if m.list.HandleMouse(msg, x, y) {
+		m.loadCurrentModel()
+	}
+	return m, nil
+}
+
+func (m Model) handleFormMouse(msg tea.MouseMsg, x, y int) (tea.Model, tea.Cmd) {
+	if msg.Action != tea.MouseActionPress || msg.Button != tea.MouseButtonLeft {
+		return m, nil
+	}
+	field := m.form.FieldAt(y)
+	if field < 0 {
+		return m, nil
+	}
+	arrow := m.form.ProviderArrowAt(x, y)
+
+	m.focusArea = FocusForm
+	m.form.SetFocusIndex(field)
+	switch arrow {
+	case -1:
+		m.form.PrevProvider()
+	case 1:
+		m.form.NextProvider()
+	}
+	return m, nil
+}
@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/diogo/droid-config/internal/config"
+)
+
+// handleRecoveryKeys drives the screen shown when the config file could not
+// be loaded. Nothing is written until the user explicitly chooses to.
+func (m Model) handleRecoveryKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, Keys.Quit, Keys.Close):
+		m.quitting = true
+		return m, tea.Quit
+
+	case key.Matches(msg, Keys.Reload):
+		cfg, err := config.Load(m.configPath)
+		if err != nil {
+			m.loadErr = err
+			m.status.SetError("Still unable to load config")
+			return m, statusClearCmd()
+		}
+		m.loadErr = nil
+		m.setConfig(cfg)
+		m.status.SetSuccess("Config reloaded")
+		return m, statusClearCmd()
+
+	case key.Matches(msg, Keys.Overwrite):
+		// Start from an empty model list; the next save replaces the file.
+		m.loadErr = nil
+		m.forceSave = true
+		m.setConfig(&config.ConfigData{CustomModels: []config.CustomModel{}})
+		m.status.SetWarning("Starting empty - next save overwrites the file")
+		return m, statusClearCmd()
+	}
+
+	return m, nil
+}
+
+func (m Model) renderRecovery() string {
+	titleStyle := TitleBackgroundStyle
+	textStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("252"))
+	codeStyle := lipgloss.NewStyle().Foreground(warningColor)
+
+	width := max(1, m.width-4)
+
+	lines := []string{
+		titleStyle.Render(padOrTruncate("CONFIG FILE COULD NOT BE LOADED", max(0, width-2))),
+		"",
+		textStyle.Render(padOrTruncate("File: "+config.DisplayPath(m.configPath), width)),
+		ErrorStyle.Render(padOrTruncate(m.loadErr.Error(), width)),
+		"",
+	}
+
+	var parseErr *config.ParseError
+	if errors.As(m.loadErr, &parseErr) {
+		radius := max(1, (m.height-14)/2)
+		for _, line := range parseErr.Context(radius) {
+			lines = append(lines, codeStyle.Render(padOrTruncate(line, width)))
+		}
+		lines = append(lines, "")
+		lines = append(lines, textStyle.Render(padOrTruncate("Nothing has been written. Fix the file in an editor and reload,", width)))
+		lines = append(lines, textStyle.Render(padOrTruncate("or start with an empty model list and overwrite it on the next save.", width)))
+		lines = append(lines, "")
+	}
+
+	lines = append(lines, HelpStyle.Render(padOrTruncate(keyHelpLine(keyHint("reload", Keys.Reload), keyHint("start empty and overwrite on save", Keys.Overwrite), keyHint("quit", Keys.Close, Keys.Quit)), width)))
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(errorColor).
+		Width(max(0, m.width-2)).
+		Height(max(0, m.height-2)).
+		Padding(0, 1).
+		Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// handleSearchKeys edits the list filter while the search input is open.
+// Enter keeps the filter and returns to the list; esc drops it.
+func (m Model) handleSearchKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, Keys.Quit):
+		return m.quit(msg)
+
+	case key.Matches(msg, Keys.Escape):
+		return m.clearSearch()
+
+	case key.Matches(msg, Keys.Enter):
+		m.list.EndSearch()
+		if !m.list.Filtering() {
+			return m, nil
+		}
+		if m.list.CurrentModel() == nil {
+			m.status.SetWarning("No models match the search")
+			return m, statusClearCmd()
+		}
+		return m, nil
+
+	case key.Matches(msg, Keys.PrevField):
+		m.list.PrevMatch()
+		m.loadCurrentModel()
+		return m, nil
+
+	case key.Matches(msg, Keys.NextField):
+		m.list.NextMatch()
+		m.loadCurrentModel()
+		return m, nil
+	}
+
+	input := m.list.SearchInput()
+	newInput, cmd := input.Update(msg)
+	m.list.UpdateSearch(newInput)
+	m.loadCurrentModel()
+	return m, cmd
+}
+
+// clearSearch removes the list filter, keeping the cursor on the same model.
+func (m Model) clearSearch() (tea.Model, tea.Cmd) {
+	filtered := m.list.Filtering()
+	m.list.ClearFilter()
+	if filtered {
+		m.status.SetInfo(fmt.Sprintf("Showing all %d models", len(m.list.Items)))
+		return m, statusClearCmd()
+	}
+	return m, nil
+}
+
+// loadCurrentModel shows the model under the list cursor in the form.
+func (m Model) loadCurrentModel() {
+	if currentModel := m.list.CurrentModel(); currentModel != nil {
+		m.form.LoadModel(currentModel)
+	}
+}
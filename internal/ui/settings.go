@@ -0,0 +1,377 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/diogo/droid-config/internal/config"
+	"github.com/diogo/droid-config/internal/ui/components"
+)
+
+// settingsMode is what the settings view is waiting for.
+type settingsMode int
+
+const (
+	settingsBrowse    settingsMode = iota
+	settingsEditValue              // a new value for the selected scalar
+	settingsAddKey                 // the key of a new member
+	settingsAddValue               // the value of a new member or element
+	settingsEditTree               // the selected value as multi-line JSON
+)
+
+// settingsView edits the top-level config keys other than custom_models as
+// a JSON tree. Every change is saved right away, like model edits.
+type settingsView struct {
+	tree   *components.JSONTree
+	mode   settingsMode
+	input  textinput.Model
+	editor textarea.Model
+	newKey string
+	err    string // why the last input was rejected
+}
+
+func (m Model) openSettings() (tea.Model, tea.Cmd) {
+	tree, err := components.NewJSONTree(m.config.Extra(), m.config.ExtraKeys())
+	if err != nil {
+		m.status.SetError("Failed to read settings: " + err.Error())
+		return m, statusClearCmd()
+	}
+
+	input := textinput.New()
+	input.CharLimit = 4096
+	editor := textarea.New()
+	editor.ShowLineNumbers = false
+	editor.CharLimit = 0
+	editor.MaxHeight = 0
+
+	m.settings = &settingsView{tree: tree, input: input, editor: editor}
+	m.layoutSettings()
+	return m, nil
+}
+
+// reloadSettings rebuilds the settings tree from the config after it was
+// replaced or restored, keeping the cursor where it was.
+func (m *Model) reloadSettings() {
+	s := m.settings
+	if s == nil {
+		return
+	}
+	tree, err := components.NewJSONTree(m.config.Extra(), m.config.ExtraKeys())
+	if err != nil {
+		m.settings = nil
+		return
+	}
+	tree.Cursor = s.tree.Cursor
+	s.tree = tree
+	s.mode = settingsBrowse
+	m.layoutSettings()
+}
+
+// layoutSettings sizes the tree and editor to the panel.
+func (m *Model) layoutSettings() {
+	s := m.settings
+	if s == nil {
+		return
+	}
+	width := max(1, m.width-4)
+	s.tree.Width = width
+	s.tree.Height = m.settingsVisibleLines()
+	s.input.Width = max(1, width-lipgloss.Width(s.input.Prompt)-1)
+	s.editor.SetWidth(width)
+	s.editor.SetHeight(m.settingsVisibleLines())
+}
+
+// settingsVisibleLines is the number of tree rows that fit in the panel,
+// leaving room for the title and a footer of up to three lines.
+func (m Model) settingsVisibleLines() int {
+	return max(1, m.contentHeight-2-2-3)
+}
+
+func (m Model) handleSettingsKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	s := m.settings
+	if key.Matches(msg, Keys.Quit) {
+		return m.quit(msg)
+	}
+
+	switch s.mode {
+	case settingsEditValue, settingsAddKey, settingsAddValue:
+		switch {
+		case key.Matches(msg, Keys.Escape):
+			s.mode = settingsBrowse
+			s.err = ""
+			return m, nil
+		case key.Matches(msg, Keys.Enter):
+			return m.submitSettingsInput()
+		}
+		var cmd tea.Cmd
+		s.input, cmd = s.input.Update(msg)
+		return m, cmd
+
+	case settingsEditTree:
+		switch {
+		case key.Matches(msg, Keys.Escape):
+			s.mode = settingsBrowse
+			s.err = ""
+			return m, nil
+		case key.Matches(msg, Keys.Save):
+			path := s.tree.CurrentPath()
+			if err := s.tree.SetCurrent([]byte(s.editor.Value())); err != nil {
+				s.err = "Invalid JSON: " + err.Error()
+				return m, nil
+			}
+			s.mode = settingsBrowse
+			s.err = ""
+			return m.applySettings("Updated " + path)
+		}
+		var cmd tea.Cmd
+		s.editor, cmd = s.editor.Update(msg)
+		return m, cmd
+	}
+
+	switch {
+	case key.Matches(msg, Keys.Escape, Keys.Close, Keys.Settings):
+		m.settings = nil
+		return m, nil
+
+	case key.Matches(msg, Keys.Up):
+		s.tree.MoveUp()
+
+	case key.Matches(msg, Keys.Down):
+		s.tree.MoveDown()
+
+	case key.Matches(msg, Keys.Left):
+		s.tree.Collapse()
+
+	case key.Matches(msg, Keys.Right):
+		s.tree.Expand()
+
+	case key.Matches(msg, Keys.Enter, Keys.Space):
+		if !s.tree.Toggle() {
+			return m.editSetting()
+		}
+
+	case key.Matches(msg, Keys.Edit):
+		return m.editSetting()
+
+	case key.Matches(msg, Keys.EditJSON):
+		if s.tree.Empty() {
+			return m, nil
+		}
+		return m.startSettingsEditor()
+
+	case key.Matches(msg, Keys.Add):
+		s.newKey = ""
+		if _, isArray := s.tree.AddTarget(); isArray {
+			return m.startSettingsInput(settingsAddValue, "null")
+		}
+		return m.startSettingsInput(settingsAddKey, "")
+
+	case key.Matches(msg, Keys.Delete):
+		if s.tree.Empty() {
+			return m, nil
+		}
+		m.askConfirm("Delete "+s.tree.CurrentPath()+"?", deleteSettingMsg{})
+
+	case key.Matches(msg, Keys.Undo):
+		return m.undo()
+
+	case key.Matches(msg, Keys.Redo):
+		return m.redo()
+	}
+	return m, nil
+}
+
+// editSetting edits the selected value: scalars on one line, objects and
+// arrays in the multi-line editor.
+func (m Model) editSetting() (tea.Model, tea.Cmd) {
+	s := m.settings
+	if s.tree.Empty() {
+		return m, nil
+	}
+	if s.tree.CurrentKind().Container() {
+		return m.startSettingsEditor()
+	}
+	return m.startSettingsInput(settingsEditValue, s.tree.CurrentJSON(""))
+}
+
+func (m Model) startSettingsInput(mode settingsMode, value string) (tea.Model, tea.Cmd) {
+	s := m.settings
+	s.mode = mode
+	s.err = ""
+	s.input.SetValue(value)
+	s.input.CursorEnd()
+	return m, s.input.Focus()
+}
+
+func (m Model) startSettingsEditor() (tea.Model, tea.Cmd) {
+	s := m.settings
+	s.mode = settingsEditTree
+	s.err = ""
+	s.editor.SetValue(s.tree.CurrentJSON("  "))
+	return m, s.editor.Focus()
+}
+
+func (m Model) submitSettingsInput() (tea.Model, tea.Cmd) {
+	s := m.settings
+	text := strings.TrimSpace(s.input.Value())
+
+	switch s.mode {
+	case settingsAddKey:
+		if err := s.tree.CanAdd(text); err != nil {
+			s.err = err.Error()
+			return m, nil
+		}
+		s.newKey = text
+		return m.startSettingsInput(settingsAddValue, "")
+
+	case settingsAddValue:
+		if err := s.tree.Add(s.newKey, settingValue(text)); err != nil {
+			s.err = err.Error()
+			return m, nil
+		}
+		s.mode = settingsBrowse
+		return m.applySettings("Added " + s.tree.CurrentPath())
+
+	default:
+		path := s.tree.CurrentPath()
+		if err := s.tree.SetCurrent(settingValue(text)); err != nil {
+			s.err = err.Error()
+			return m, nil
+		}
+		s.mode = settingsBrowse
+		return m.applySettings("Updated " + path)
+	}
+}
+
+// settingValue reads typed input as JSON, so 42, true and {"a":1} keep
+// their type; anything else is taken as a string. Input that starts like a
+// JSON string, object or array is kept as typed, so a typo in it is rejected
+// instead of saved as text.
+func settingValue(text string) []byte {
+	if json.Valid([]byte(text)) || strings.HasPrefix(text, `"`) || strings.HasPrefix(text, "{") || strings.HasPrefix(text, "[") {
+		return []byte(text)
+	}
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(text)
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n"))
+}
+
+// deleteSettingMsg is sent when deleting the selected setting was confirmed.
+type deleteSettingMsg struct{}
+
+func (m Model) deleteSetting() (tea.Model, tea.Cmd) {
+	s := m.settings
+	if s == nil {
+		return m, nil
+	}
+	path := s.tree.CurrentPath()
+	if !s.tree.DeleteCurrent() {
+		return m, nil
+	}
+	return m.applySettings("Deleted " + path)
+}
+
+// applySettings stores the edited tree in the config and saves it.
+func (m Model) applySettings(message string) (tea.Model, tea.Cmd) {
+	m.history.push(m.snapshot())
+	m.config.SetExtra(m.settings.tree.Values())
+	m.dirty = true
+	m.status.SetSuccess(message)
+	return m.saveConfig()
+}
+
+// updateSettingsInput passes other messages, such as cursor blinks, to the
+// active input.
+func (m Model) updateSettingsInput(msg tea.Msg) tea.Cmd {
+	s := m.settings
+	var cmd tea.Cmd
+	switch s.mode {
+	case settingsEditValue, settingsAddKey, settingsAddValue:
+		s.input, cmd = s.input.Update(msg)
+	case settingsEditTree:
+		s.editor, cmd = s.editor.Update(msg)
+	}
+	return cmd
+}
+
+func (m Model) renderSettings() string {
+	s := m.settings
+	width := max(1, m.width-4)
+
+	title := "SETTINGS - " + config.DisplayPath(m.configPath)
+	if s.mode == settingsEditTree {
+		title = "EDITING " + s.tree.CurrentPath() + " (JSON)"
+	}
+	lines := []string{
+		TitleBackgroundStyle.Render(padOrTruncate(title, max(0, width-2))),
+		"",
+	}
+
+	var footer []string
+	switch {
+	case s.mode == settingsEditTree:
+		lines = append(lines, s.editor.View())
+	case s.tree.Empty():
+		lines = append(lines, HintStyle.Render(padOrTruncate("  No other settings yet - press "+Keys.Add.Help().Key+" to add one", width)))
+	default:
+		lines = append(lines, s.tree.View(true))
+	}
+
+	switch s.mode {
+	case settingsBrowse:
+		if !s.tree.Empty() {
+			footer = append(footer, DimmedStyle.Render(padOrTruncate(s.tree.CurrentPath(), width)))
+		}
+	case settingsEditValue, settingsAddKey, settingsAddValue:
+		footer = append(footer, LabelStyle.Render(padOrTruncate(m.settingsPrompt(), width)))
+		footer = append(footer, s.input.View())
+	}
+	if s.err != "" {
+		footer = append(footer, ErrorStyle.Render(padOrTruncate(s.err, width)))
+	}
+
+	body := strings.Join(lines, "\n")
+	if len(footer) > 0 {
+		// Pin the footer to the bottom of the panel.
+		gap := max(0, m.contentHeight-2-lipgloss.Height(body)-len(footer))
+		body += strings.Repeat("\n", gap+1) + strings.Join(footer, "\n")
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(primaryColor).
+		Width(max(0, m.width-2)).
+		Height(max(0, m.contentHeight-2)).
+		Padding(0, 1).
+		Render(body)
+}
+
+// settingsPrompt labels the input line for the current mode.
+func (m Model) settingsPrompt() string {
+	s := m.settings
+	switch s.mode {
+	case settingsAddKey:
+		if path, _ := s.tree.AddTarget(); path != "" {
+			return "New key in " + path + ":"
+		}
+		return "New top-level key:"
+	case settingsAddValue:
+		path, isArray := s.tree.AddTarget()
+		switch {
+		case isArray:
+			return "New element of " + path + " (JSON, or text for a string):"
+		case path != "":
+			return "Value of " + path + "." + s.newKey + " (JSON, or text for a string):"
+		}
+		return "Value of " + s.newKey + " (JSON, or text for a string):"
+	}
+	return "Value of " + s.tree.CurrentPath() + " (JSON, or text for a string):"
+}
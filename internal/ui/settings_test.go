@@ -0,0 +1,36 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/diogo/droid-config/internal/ui/components"
+)
+
+func TestSettingValue(t *testing.T) {
+	tests := map[string]string{
+		`42`:       `42`,
+		`true`:     `true`,
+		`{"a": 1}`: `{"a": 1}`,
+		`"dark"`:   `"dark"`,
+		`dark`:     `"dark"`,
+		``:         `""`,
+		`a <b> c`:  `"a <b> c"`,
+	}
+	for text, want := range tests {
+		if got := string(settingValue(text)); got != want {
+			t.Errorf("Expected %q to be read as %s, got %s", text, want, got)
+		}
+	}
+}
+
+func TestSettingValueRejectsBrokenJSON(t *testing.T) {
+	tree, err := components.NewJSONTree(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, text := range []string{`""42`, `"x" y`, `{"a": 1`, `[1,`} {
+		if err := tree.Add("k", settingValue(text)); err == nil {
+			t.Errorf("Expected %q to be rejected, got %s", text, tree.Values()["k"])
+		}
+	}
+}
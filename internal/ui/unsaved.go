@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diogo/droid-config/internal/config"
+	"github.com/diogo/droid-config/internal/ui/components"
+)
+
+// unsavedChoiceMsg is the answer to the unsaved changes prompt.
+type unsavedChoiceMsg struct {
+	save bool
+}
+
+// leavesForm reports whether msg would leave the form or reload it, throwing
+// away edits that were not saved yet.
+func (m Model) leavesForm(msg tea.KeyMsg) bool {
+	if m.focusArea != FocusForm {
+		return false
+	}
+	switch {
+	case key.Matches(msg, Keys.Escape, Keys.Undo, Keys.Redo):
+		return true
+	case key.Matches(msg, Keys.ShiftTab):
+		return m.form.FocusIndex() == 0
+	}
+	return false
+}
+
+// quit exits, first asking to save form edits or changes that were not
+// written to the config file yet.
+func (m Model) quit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.dirty || m.form.Dirty() {
+		return m.promptUnsaved(msg)
+	}
+	m.quitting = true
+	return m, tea.Quit
+}
+
+// promptUnsaved asks whether to save or discard the form edits, or the
+// changes not written to the config file, before msg, a key or a click, is
+// handled.
+func (m Model) promptUnsaved(msg tea.Msg) (tea.Model, tea.Cmd) {
+	message := "Save changes to " + config.DisplayPath(m.configPath) + "?"
+	if m.form.Dirty() {
+		name := "this model"
+		if current := m.list.CurrentModel(); current != nil && current.DisplayName != "" {
+			name = "\"" + current.DisplayName + "\""
+		}
+		message = "Save changes to " + name + "?"
+	}
+	m.pending = msg
+	m.dialog.Show("UNSAVED CHANGES", message,
+		components.DialogButton{Label: buttonLabel("Save", Keys.SaveEdits), Key: Keys.SaveEdits, Msg: components.Reply(unsavedChoiceMsg{save: true})},
+		components.DialogButton{Label: buttonLabel("Discard", Keys.Discard), Key: Keys.Discard, Msg: components.Reply(unsavedChoiceMsg{})},
+		components.DialogButton{Label: buttonLabel("Cancel", Keys.Escape), Key: Keys.Keep, Cancel: true},
+	)
+	return m, nil
+}
+
+// resolveUnsaved saves or discards the form edits, then handles the key or
+// click that was held back by the prompt.
+func (m Model) resolveUnsaved(save bool) (tea.Model, tea.Cmd) {
+	pending := m.pending
+	m.pending = nil
+
+	if !save {
+		m.form.Discard()
+		if msg, ok := pending.(tea.KeyMsg); ok && key.Matches(msg, Keys.Quit) {
+			m.quitting = true
+			return m, tea.Quit
+		}
+		if pending == nil {
+			return m, nil
+		}
+		return m.Update(pending)
+	}
+
+	var next tea.Model
+	var cmd tea.Cmd
+	if m.form.Dirty() {
+		next, cmd = m.saveCurrentModel()
+	} else {
+		next, cmd = m.saveConfig()
+	}
+	saved := next.(Model)
+	// Stay put if the save was refused or is waiting on another prompt.
+	if pending == nil || saved.form.Dirty() || saved.dirty || saved.external != nil {
+		return saved, cmd
+	}
+	next, replay := saved.Update(pending)
+	return next, tea.Batch(cmd, replay)
+}
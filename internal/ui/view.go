@@ -3,8 +3,10 @@ package ui
 import (
 	"strings"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/x/ansi"
+	"github.com/diogo/droid-config/internal/config"
 )
 
 func (m Model) View() string {
@@ -16,6 +18,10 @@ func (m Model) View() string {
 		return "Loading...\n"
 	}
 
+	if m.loadErr != nil {
+		return m.renderRecovery()
+	}
+
 	primaryColor := lipgloss.Color("39")
 	secondaryColor := lipgloss.Color("240")
 
@@ -58,7 +64,17 @@ func (m Model) View() string {
 	form := formStyle.Render(formContent)
 
 	var content string
-	if m.stackedLayout {
+	if m.backups != nil {
+		content = m.renderBackups()
+	} else if m.importer != nil {
+		content = m.renderImport()
+	} else if m.exporter != nil {
+		content = m.renderExport()
+	} else if m.bulkEdit != nil {
+		content = m.renderBulkEdit()
+	} else if m.settings != nil {
+		content = m.renderSettings()
+	} else if m.stackedLayout {
 		content = lipgloss.JoinVertical(lipgloss.Left, sidebar, form)
 	} else {
 		content = lipgloss.JoinHorizontal(lipgloss.Top, sidebar, form)
@@ -71,31 +87,85 @@ func (m Model) View() string {
 		Padding(0, 1)
 
 	statusLineWidth := max(0, m.width-4)
-	statusContent := padOrTruncate("Status: "+m.status.View(), statusLineWidth)
+	statusContent := m.renderStatusLine(statusLineWidth)
 	statusBar := statusStyle.Render(statusContent)
 
 	helpStyle := lipgloss.NewStyle().
 		Foreground(secondaryColor).
 		Padding(0, 1)
 
-	helpText := "tab: form | ↑↓/jk: nav | space: select | a: all | n: new | d: del | ctrl+↑↓: move | ctrl+s: save | ctrl+c: quit"
-	if m.focusArea == FocusForm {
-		helpText = "tab/↑↓: fields | ←→: provider | ctrl+v: show key | ctrl+s: save | esc: back | ctrl+c: quit"
+	k := Keys
+	helpText := keyHelpLine(
+		keyHint("form", k.Tab), keyHint("nav", k.Up, k.Down), keyHint("select", k.Space), keyHint("all", k.SelectAll),
+		keyHint("search", k.Search), keyHint("view", k.ViewMode), keyHint("apply sort", k.ApplySort),
+		keyHint("new", k.NewModel), keyHint("clone", k.Clone), keyHint("bulk edit", k.BulkEdit), keyHint("del", k.Delete),
+		keyHint("rename", k.Rename), keyHint("move", k.MoveUp, k.MoveDown), keyHint("undo/redo", k.Undo, k.Redo),
+		keyHint("backups", k.Backups), keyHint("import", k.Import), keyHint("export", k.Export),
+		keyHint("settings", k.Settings), keyHint("save", k.Save), keyHint("quit", k.Quit),
+	)
+	if m.backups != nil {
+		helpText = keyHelpLine(keyHint("nav", k.Up, k.Down), keyHint("diff", k.Enter, k.Diff), keyHint("restore", k.Restore),
+			keyHint("back", k.Escape), keyHint("quit", k.Quit))
+	} else if m.importer != nil && m.importer.previewing() {
+		helpText = keyHelpLine(keyHint("nav", k.Up, k.Down), keyHint("toggle", k.Space), keyHint("all", k.SelectAll),
+			keyHint("import selected", k.Enter), keyHint("back", k.Escape), keyHint("quit", k.Quit))
+	} else if m.importer != nil {
+		helpText = keyHelpLine(keyHint("preview", k.Enter), keyHint("cancel", k.Escape), keyHint("quit", k.Quit))
+	} else if m.exporter != nil {
+		helpText = keyHelpLine(keyHint("key handling", k.Tab), keyHint("export", k.Enter), keyHint("cancel", k.Escape), keyHint("quit", k.Quit))
+	} else if m.bulkEdit != nil && m.bulkEdit.previewing() {
+		helpText = keyHelpLine(keyHint("scroll", k.Up, k.Down), keyHint("apply and save", k.Enter), keyHint("back", k.Escape), keyHint("quit", k.Quit))
+	} else if m.bulkEdit != nil {
+		helpText = keyHelpLine(keyHint("fields", k.Tab, k.PrevField, k.NextField), keyHint("provider", k.Left, k.Right),
+			keyHint("preview", k.Enter), keyHint("cancel", k.Escape), keyHint("quit", k.Quit))
+	} else if m.settings != nil && m.settings.mode == settingsEditTree {
+		helpText = keyHelpLine(keyHint("apply and save", k.Save), keyHint("cancel", k.Escape), keyHint("quit", k.Quit))
+	} else if m.settings != nil && m.settings.mode != settingsBrowse {
+		helpText = keyHelpLine(keyHint("apply and save", k.Enter), keyHint("cancel", k.Escape), keyHint("quit", k.Quit))
+	} else if m.settings != nil {
+		helpText = keyHelpLine(keyHint("nav", k.Up, k.Down), keyHint("collapse/expand", k.Left, k.Right),
+			keyHint("toggle/edit", k.Enter), keyHint("edit", k.Edit), keyHint("edit as JSON", k.EditJSON), keyHint("add", k.Add),
+			keyHint("delete", k.Delete), keyHint("undo/redo", k.Undo, k.Redo), keyHint("back", k.Escape), keyHint("quit", k.Quit))
+	} else if m.list.Searching() {
+		helpText = "type to filter | " + keyHelpLine(keyHint("matches", k.PrevField, k.NextField), keyHint("keep filter", k.Enter),
+			keyHint("clear", k.Escape), keyHint("quit", k.Quit))
+	} else if m.focusArea == FocusSidebar && m.list.Filtering() {
+		helpText = keyHelpLine(keyHint("next/prev match", k.NextMatch, k.PrevMatch), keyHint("edit search", k.Search),
+			keyHint("clear filter", k.Escape), keyHint("form", k.Tab), keyHint("select", k.Space), keyHint("del", k.Delete),
+			keyHint("save", k.Save), keyHint("quit", k.Quit))
+	} else if m.focusArea == FocusForm {
+		helpText = keyHelpLine(keyHint("fields", k.Tab, k.PrevField, k.NextField), keyHint("provider", k.Left, k.Right),
+			keyHint("show key", k.ToggleKey), keyHint("test", k.Test), keyHint("models", k.PickModel), keyHint("save", k.Save),
+			keyHint("back", k.Escape), keyHint("quit", k.Quit))
 	}
 	helpBar := helpStyle.Render(padOrTruncate(helpText, max(0, m.width-2)))
 
 	full := lipgloss.JoinVertical(lipgloss.Left, content, statusBar, helpBar)
 
-	if m.confirm.Active {
-		return m.renderWithModal(full)
+	if m.dialog.Active {
+		return m.renderWithModal(m.dialog.View())
+	}
+	if m.picker.Active {
+		return m.renderWithModal(m.picker.View())
 	}
 
 	return full
 }
 
-func (m Model) renderWithModal(background string) string {
-	modalView := m.confirm.View()
+// renderStatusLine shows the status message with the active config path
+// right-aligned, dropping the path when the line is too narrow for both.
+func (m Model) renderStatusLine(width int) string {
+	left := "Status: " + m.status.View()
+	path := DimmedStyle.Render(config.DisplayPath(m.configPath))
+
+	gap := width - lipgloss.Width(left) - lipgloss.Width(path)
+	if gap < 2 {
+		return padOrTruncate(left, width)
+	}
+	return left + strings.Repeat(" ", gap) + path
+}
 
+func (m Model) renderWithModal(modalView string) string {
 	// Use lipgloss.Place to center the modal over the background
 	return lipgloss.Place(
 		m.width,
@@ -108,6 +178,32 @@ func (m Model) renderWithModal(background string) string {
 	)
 }
 
+// keyHint describes bindings for the help line, e.g. "↑/k ↓/j: nav".
+// Disabled bindings are left out, and so is a hint without any.
+func keyHint(desc string, bindings ...key.Binding) string {
+	var keys []string
+	for _, b := range bindings {
+		if b.Enabled() {
+			keys = append(keys, b.Help().Key)
+		}
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	return strings.Join(keys, " ") + ": " + desc
+}
+
+// keyHelpLine joins hints for the help line.
+func keyHelpLine(hints ...string) string {
+	var parts []string
+	for _, h := range hints {
+		if h != "" {
+			parts = append(parts, h)
+		}
+	}
+	return strings.Join(parts, " | ")
+}
+
 func padOrTruncate(s string, width int) string {
 	if width <= 0 {
 		return ""